@@ -31,6 +31,11 @@ import (
 type Model struct {
 	assertionBase
 	classic          bool
+	gadgetFirst      bool
+	gadget           string
+	gadgetTrack      string
+	kernel           string
+	kernelTrack      string
 	requiredSnaps    []string
 	sysUserAuthority []string
 	timestamp        time.Time
@@ -73,12 +78,30 @@ func (mod *Model) Architecture() string {
 
 // Gadget returns the gadget snap the model uses.
 func (mod *Model) Gadget() string {
-	return mod.HeaderString("gadget")
+	return mod.gadget
+}
+
+// GadgetTrack returns the track the gadget snap of the model must
+// come from, or empty if the model does not constrain it.
+func (mod *Model) GadgetTrack() string {
+	return mod.gadgetTrack
+}
+
+// GadgetFirst returns whether the gadget snap of the model must be
+// seeded before its kernel snap.
+func (mod *Model) GadgetFirst() bool {
+	return mod.gadgetFirst
 }
 
 // Kernel returns the kernel snap the model uses.
 func (mod *Model) Kernel() string {
-	return mod.HeaderString("kernel")
+	return mod.kernel
+}
+
+// KernelTrack returns the track the kernel snap of the model must
+// come from, or empty if the model does not constrain it.
+func (mod *Model) KernelTrack() string {
+	return mod.kernelTrack
 }
 
 // Store returns the snap store the model uses.
@@ -86,6 +109,12 @@ func (mod *Model) Store() string {
 	return mod.HeaderString("store")
 }
 
+// Grade returns the grade of the model: signed|dangerous, or empty
+// if the model does not specify one.
+func (mod *Model) Grade() string {
+	return mod.HeaderString("grade")
+}
+
 // RequiredSnaps returns the snaps that must be installed at all times and cannot be removed for this model.
 func (mod *Model) RequiredSnaps() []string {
 	return mod.requiredSnaps
@@ -160,6 +189,21 @@ var (
 	classicModelOptional = []string{"architecture", "gadget"}
 )
 
+// splitSnapTrack splits the value of a header naming a snap, optionally
+// followed by =track to constrain the track the snap must come from.
+func splitSnapTrack(headers map[string]interface{}, name string) (snapName, track string, err error) {
+	value, _ := headers[name].(string)
+	i := strings.IndexRune(value, '=')
+	if i < 0 {
+		return value, "", nil
+	}
+	snapName, track = value[:i], value[i+1:]
+	if snapName == "" || track == "" || strings.ContainsAny(track, "/=") {
+		return "", "", fmt.Errorf("%q header must be a snap name optionally followed by =track: %q", name, value)
+	}
+	return snapName, track, nil
+}
+
 func assembleModel(assert assertionBase) (Assertion, error) {
 	err := checkAuthorityMatchesBrand(&assert)
 	if err != nil {
@@ -195,6 +239,15 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 		}
 	}
 
+	gadget, gadgetTrack, err := splitSnapTrack(assert.headers, "gadget")
+	if err != nil {
+		return nil, err
+	}
+	kernel, kernelTrack, err := splitSnapTrack(assert.headers, "kernel")
+	if err != nil {
+		return nil, err
+	}
+
 	// store is optional but must be a string, defaults to the ubuntu store
 	_, err = checkOptionalString(assert.headers, "store")
 	if err != nil {
@@ -207,6 +260,23 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 		return nil, err
 	}
 
+	// grade is optional but must be one of the known ones
+	grade, err := checkOptionalString(assert.headers, "grade")
+	if err != nil {
+		return nil, err
+	}
+	switch grade {
+	case "", "signed", "dangerous":
+	default:
+		return nil, fmt.Errorf(`"grade" header must be one of signed|dangerous: %q`, grade)
+	}
+
+	// gadget-first is optional and defaults to seeding the kernel first
+	gadgetFirst, err := checkOptionalBool(assert.headers, "gadget-first")
+	if err != nil {
+		return nil, err
+	}
+
 	reqSnaps, err := checkStringList(assert.headers, "required-snaps")
 	if err != nil {
 		return nil, err
@@ -233,6 +303,11 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 	return &Model{
 		assertionBase:    assert,
 		classic:          classic,
+		gadgetFirst:      gadgetFirst,
+		gadget:           gadget,
+		gadgetTrack:      gadgetTrack,
+		kernel:           kernel,
+		kernelTrack:      kernelTrack,
 		requiredSnaps:    reqSnaps,
 		sysUserAuthority: sysUserAuthority,
 		timestamp:        timestamp,
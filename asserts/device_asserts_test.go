@@ -138,6 +138,57 @@ func (mods *modelSuite) TestDecodeDisplayNameIsOptional(c *C) {
 	c.Check(model.DisplayName(), Equals, "baz-3000")
 }
 
+func (mods *modelSuite) TestDecodeGradeIsOptional(c *C) {
+	withTimestamp := strings.Replace(modelExample, "TSLINE", mods.tsLine, 1)
+	a, err := asserts.Decode([]byte(withTimestamp))
+	c.Assert(err, IsNil)
+	model := a.(*asserts.Model)
+	c.Check(model.Grade(), Equals, "")
+
+	for _, grade := range []string{"signed", "dangerous"} {
+		encoded := strings.Replace(withTimestamp, "store: brand-store\n", "store: brand-store\ngrade: "+grade+"\n", 1)
+		a, err = asserts.Decode([]byte(encoded))
+		c.Assert(err, IsNil)
+		model = a.(*asserts.Model)
+		c.Check(model.Grade(), Equals, grade)
+	}
+}
+
+func (mods *modelSuite) TestDecodeGadgetFirstIsOptional(c *C) {
+	withTimestamp := strings.Replace(modelExample, "TSLINE", mods.tsLine, 1)
+	a, err := asserts.Decode([]byte(withTimestamp))
+	c.Assert(err, IsNil)
+	model := a.(*asserts.Model)
+	c.Check(model.GadgetFirst(), Equals, false)
+
+	encoded := strings.Replace(withTimestamp, "store: brand-store\n", "store: brand-store\ngadget-first: true\n", 1)
+	a, err = asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	model = a.(*asserts.Model)
+	c.Check(model.GadgetFirst(), Equals, true)
+}
+
+func (mods *modelSuite) TestDecodeTracksAreOptional(c *C) {
+	withTimestamp := strings.Replace(modelExample, "TSLINE", mods.tsLine, 1)
+	a, err := asserts.Decode([]byte(withTimestamp))
+	c.Assert(err, IsNil)
+	model := a.(*asserts.Model)
+	c.Check(model.Gadget(), Equals, "brand-gadget")
+	c.Check(model.GadgetTrack(), Equals, "")
+	c.Check(model.Kernel(), Equals, "baz-linux")
+	c.Check(model.KernelTrack(), Equals, "")
+
+	encoded := strings.Replace(withTimestamp, "gadget: brand-gadget\n", "gadget: brand-gadget=18\n", 1)
+	encoded = strings.Replace(encoded, "kernel: baz-linux\n", "kernel: baz-linux=4.4\n", 1)
+	a, err = asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	model = a.(*asserts.Model)
+	c.Check(model.Gadget(), Equals, "brand-gadget")
+	c.Check(model.GadgetTrack(), Equals, "18")
+	c.Check(model.Kernel(), Equals, "baz-linux")
+	c.Check(model.KernelTrack(), Equals, "4.4")
+}
+
 func (mods *modelSuite) TestDecodeRequiredSnapsAreOptional(c *C) {
 	withTimestamp := strings.Replace(modelExample, "TSLINE", mods.tsLine, 1)
 	encoded := strings.Replace(withTimestamp, reqSnaps, "", 1)
@@ -188,7 +239,14 @@ func (mods *modelSuite) TestDecodeInvalid(c *C) {
 		{"gadget: brand-gadget\n", "gadget: \n", `"gadget" header should not be empty`},
 		{"kernel: baz-linux\n", "", `"kernel" header is mandatory`},
 		{"kernel: baz-linux\n", "kernel: \n", `"kernel" header should not be empty`},
+		{"kernel: baz-linux\n", "kernel: baz-linux=\n", `"kernel" header must be a snap name optionally followed by =track: "baz-linux="`},
+		{"kernel: baz-linux\n", "kernel: =18\n", `"kernel" header must be a snap name optionally followed by =track: "=18"`},
+		{"kernel: baz-linux\n", "kernel: baz-linux=18/stable\n", `"kernel" header must be a snap name optionally followed by =track: "baz-linux=18/stable"`},
+		{"gadget: brand-gadget\n", "gadget: brand-gadget=18=1\n", `"gadget" header must be a snap name optionally followed by =track: "brand-gadget=18=1"`},
 		{"store: brand-store\n", "store:\n  - xyz\n", `"store" header must be a string`},
+		{"store: brand-store\n", "grade:\n  - xyz\n", `"grade" header must be a string`},
+		{"store: brand-store\n", "grade: devel\n", `"grade" header must be one of signed\|dangerous: "devel"`},
+		{"store: brand-store\n", "gadget-first: yes\n", `"gadget-first" header must be 'true' or 'false'`},
 		{mods.tsLine, "", `"timestamp" header is mandatory`},
 		{mods.tsLine, "timestamp: \n", `"timestamp" header should not be empty`},
 		{mods.tsLine, "timestamp: 12:30\n", `"timestamp" header is not a RFC3339 date: .*`},
@@ -2303,6 +2303,17 @@ func postDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		return SyncResponse(map[string]interface{}{
 			"base-declaration": string(asserts.Encode(bd)),
 		}, nil)
+	case "get-seed-time":
+		seedTime, err := devicestate.SeedTime(st)
+		if err == state.ErrNoState {
+			return NotFound("device is not seeded yet")
+		}
+		if err != nil {
+			return InternalError("cannot get seed time: %s", err)
+		}
+		return SyncResponse(map[string]interface{}{
+			"seed-time": seedTime,
+		}, nil)
 	default:
 		return BadRequest("unknown debug action: %v", a.Action)
 	}
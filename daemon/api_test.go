@@ -5692,6 +5692,35 @@ func (s *postDebugSuite) TestPostDebugGetBaseDeclaration(c *check.C) {
 		testutil.Contains, "type: base-declaration")
 }
 
+func (s *postDebugSuite) TestPostDebugGetSeedTime(c *check.C) {
+	d := s.daemon(c)
+
+	buf := bytes.NewBufferString(`{"action": "get-seed-time"}`)
+	req, err := http.NewRequest("POST", "/v2/debug", buf)
+	c.Assert(err, check.IsNil)
+
+	// not seeded yet
+	rsp := postDebug(debugCmd, req, nil).(*resp)
+	c.Check(rsp.Type, check.Equals, ResponseTypeError)
+	c.Check(rsp.Status, check.Equals, 404)
+
+	seedTime := time.Date(2017, 10, 16, 12, 0, 0, 0, time.UTC)
+	st := d.overlord.State()
+	st.Lock()
+	st.Set("seed-time", seedTime)
+	st.Unlock()
+
+	buf = bytes.NewBufferString(`{"action": "get-seed-time"}`)
+	req, err = http.NewRequest("POST", "/v2/debug", buf)
+	c.Assert(err, check.IsNil)
+
+	rsp = postDebug(debugCmd, req, nil).(*resp)
+	c.Check(rsp.Type, check.Equals, ResponseTypeSync)
+	c.Check(rsp.Result, check.DeepEquals, map[string]interface{}{
+		"seed-time": seedTime,
+	})
+}
+
 type appSuite struct {
 	apiBaseSuite
 	cmd *testutil.MockCmd
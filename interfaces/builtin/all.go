@@ -40,6 +40,60 @@ func Interfaces() []interfaces.Interface {
 	return ifaces
 }
 
+// ImplicitInterfaces holds the names of the built-in interfaces that
+// the OS snap provides implicitly.
+type ImplicitInterfaces struct {
+	// OnCore lists the interfaces implicitly provided on core systems.
+	OnCore []string
+	// OnClassic lists the interfaces implicitly provided on classic
+	// systems.
+	OnClassic []string
+	// ReservedForOS lists the interfaces whose slots only the OS
+	// snap can have.
+	ReservedForOS []string
+}
+
+// ImplicitOSInterfaces returns the names of the built-in interfaces,
+// sorted, grouped by whether they are implicitly provided by the OS
+// snap on core and on classic and whether they are reserved for it.
+func ImplicitOSInterfaces() *ImplicitInterfaces {
+	implicit := &ImplicitInterfaces{}
+	for _, iface := range Interfaces() {
+		si := interfaces.StaticInfoOf(iface)
+		if si.ImplicitOnCore {
+			implicit.OnCore = append(implicit.OnCore, iface.Name())
+		}
+		if si.ImplicitOnClassic {
+			implicit.OnClassic = append(implicit.OnClassic, iface.Name())
+		}
+		if isReservedForOS(iface) {
+			implicit.ReservedForOS = append(implicit.ReservedForOS, iface.Name())
+		}
+	}
+	return implicit
+}
+
+// IsReservedForOS returns whether only the OS snap can have slots of
+// the named built-in interface.
+func IsReservedForOS(name string) bool {
+	iface := allInterfaces[name]
+	if iface == nil {
+		return false
+	}
+	return isReservedForOS(iface)
+}
+
+// osReserver is implemented by the interfaces that can tell whether
+// their slots are reserved for the OS snap.
+type osReserver interface {
+	isReservedForOS() bool
+}
+
+func isReservedForOS(iface interfaces.Interface) bool {
+	reserver, ok := iface.(osReserver)
+	return ok && reserver.isReservedForOS()
+}
+
 // registerIface appends the given interface into the list of all known interfaces.
 func registerIface(iface interfaces.Interface) {
 	if allInterfaces[iface.Name()] != nil {
@@ -32,6 +32,8 @@ import (
 	"github.com/snapcore/snapd/interfaces/seccomp"
 	"github.com/snapcore/snapd/interfaces/systemd"
 	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
 
 	. "gopkg.in/check.v1"
 )
@@ -307,3 +309,68 @@ func (s *AllSuite) TestRegisterIface(c *C) {
 	// Duplicates are detected.
 	c.Assert(func() { builtin.RegisterIface(iface) }, PanicMatches, `cannot register duplicate interface "foo"`)
 }
+
+func (s *AllSuite) TestImplicitOSInterfaces(c *C) {
+	implicit := builtin.ImplicitOSInterfaces()
+	c.Check(implicit.OnCore, testutil.Contains, "timeserver-control")
+	c.Check(implicit.OnClassic, testutil.Contains, "timeserver-control")
+	c.Check(implicit.ReservedForOS, testutil.Contains, "timeserver-control")
+
+	// modem-manager is only implicit on classic and can be provided
+	// by app snaps
+	c.Check(implicit.OnCore, Not(testutil.Contains), "modem-manager")
+	c.Check(implicit.OnClassic, testutil.Contains, "modem-manager")
+	c.Check(implicit.ReservedForOS, Not(testutil.Contains), "modem-manager")
+}
+
+func (s *AllSuite) TestIsReservedForOS(c *C) {
+	c.Check(builtin.IsReservedForOS("timeserver-control"), Equals, true)
+	c.Check(builtin.IsReservedForOS("modem-manager"), Equals, false)
+	c.Check(builtin.IsReservedForOS("no-such-interface"), Equals, false)
+
+	// interfaces not built on commonInterface tell about it as well
+	c.Check(builtin.IsReservedForOS("desktop"), Equals, true)
+	c.Check(builtin.IsReservedForOS("unity7"), Equals, true)
+	c.Check(builtin.IsReservedForOS("ubuntu-download-manager"), Equals, false)
+	implicit := builtin.ImplicitOSInterfaces()
+	c.Check(implicit.ReservedForOS, testutil.Contains, "desktop")
+	c.Check(implicit.ReservedForOS, testutil.Contains, "unity7")
+}
+
+func (s *AllSuite) TestReservedForOSMatchesSanitizeSlot(c *C) {
+	// the interfaces reserved for the OS reject the slots of app snaps
+	for _, iface := range builtin.Interfaces() {
+		if !builtin.IsReservedForOS(iface.Name()) {
+			continue
+		}
+		slot := &interfaces.Slot{SlotInfo: &snap.SlotInfo{
+			Snap:      &snap.Info{SuggestedName: "some-snap", Type: snap.TypeApp},
+			Name:      iface.Name(),
+			Interface: iface.Name(),
+		}}
+		c.Check(slot.Sanitize(iface), ErrorMatches, ".* slots are reserved for the core snap", Commentf(iface.Name()))
+	}
+}
+
+func (s *AllSuite) TestReservedForOSSlotsRejected(c *C) {
+	repo := interfaces.NewRepository()
+	c.Assert(repo.AddInterface(builtin.MustInterface("timeserver-control")), IsNil)
+
+	// an app snap cannot provide the slot
+	slot := builtin.MockSlot(c, `name: producer
+slots:
+  timeserver-control:
+`, nil, "timeserver-control")
+	err := repo.AddSlot(slot)
+	c.Assert(err, ErrorMatches, "cannot add slot: timeserver-control slots are reserved for the core snap")
+	c.Check(repo.Slots("producer"), HasLen, 0)
+
+	// the core snap can
+	slot = builtin.MockSlot(c, `name: core
+type: os
+slots:
+  timeserver-control:
+`, nil, "timeserver-control")
+	c.Assert(repo.AddSlot(slot), IsNil)
+	c.Check(repo.Slots("core"), HasLen, 1)
+}
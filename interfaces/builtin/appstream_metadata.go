@@ -0,0 +1,57 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const appstreamMetadataSummary = `allows read access to the AppStream metadata of the host system`
+
+const appstreamMetadataBaseDeclarationSlots = `
+  appstream-metadata:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const appstreamMetadataConnectedPlugAppArmor = `
+# Description: Can read the AppStream software catalog metadata of the host
+# system; see https://www.freedesktop.org/software/appstream/docs/
+# The metadata directories commonly symlink to each other, so all of them are
+# allowed in full for the symlink targets to be reachable.
+
+# /usr/share of the host is only reachable through hostfs
+/var/lib/snapd/hostfs/usr/share/app-info/ r,
+/var/lib/snapd/hostfs/usr/share/app-info/** r,
+
+/var/lib/app-info/ r,
+/var/lib/app-info/** r,
+/var/cache/app-info/ r,
+/var/cache/app-info/** r,
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "appstream-metadata",
+		summary:               appstreamMetadataSummary,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  appstreamMetadataBaseDeclarationSlots,
+		connectedPlugAppArmor: appstreamMetadataConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
@@ -0,0 +1,136 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/snapcore/snapd/interfaces"
+)
+
+const blockDevicesSummary = `allows raw access to the block device named by the slot`
+
+const blockDevicesBaseDeclarationSlots = `
+  block-devices:
+    allow-installation:
+      slot-snap-type:
+        - core
+        - gadget
+    deny-auto-connection: true
+`
+
+// Pattern to match the whole disk block device nodes, device attributes
+// will be compared to this to tell disks and partitions apart
+var blockDevicesDiskPattern = regexp.MustCompile("^/dev/(sd[a-z]{1,2}|vd[a-z]{1,2}|xvd[a-z]{1,2}|nvme[0-9]{1,3}n[0-9]{1,3}|mmcblk[0-9]{1,3})$")
+
+// Pattern to match the single partition block device nodes
+var blockDevicesPartitionPattern = regexp.MustCompile("^/dev/(sd[a-z]{1,2}[0-9]{1,3}|vd[a-z]{1,2}[0-9]{1,3}|xvd[a-z]{1,2}[0-9]{1,3}|nvme[0-9]{1,3}n[0-9]{1,3}p[0-9]{1,3}|mmcblk[0-9]{1,3}p[0-9]{1,3})$")
+
+// blockDevicesAttrs returns the device attribute of the slot and
+// whether access to the partitions of the device is allowed as well,
+// checking that they are valid.
+func blockDevicesAttrs(slot *interfaces.Slot) (device string, partitions bool, err error) {
+	device, ok := slot.Attrs["device"].(string)
+	if !ok || device == "" {
+		return "", false, fmt.Errorf("block-devices slot must have a device attribute")
+	}
+	if v, ok := slot.Attrs["partitions"]; ok {
+		if partitions, ok = v.(bool); !ok {
+			return "", false, fmt.Errorf("block-devices partitions attribute must be a boolean")
+		}
+	}
+	if filepath.Clean(device) != device {
+		return "", false, fmt.Errorf("block-devices device attribute must be a clean path: %q", device)
+	}
+	switch {
+	case blockDevicesDiskPattern.MatchString(device):
+	case blockDevicesPartitionPattern.MatchString(device):
+		if partitions {
+			return "", false, fmt.Errorf("block-devices partitions attribute needs a whole disk device: %q", device)
+		}
+	default:
+		return "", false, fmt.Errorf("block-devices device attribute must name a single block device: %q", device)
+	}
+	return device, partitions, nil
+}
+
+// blockDevicesPartitionsGlob returns the glob matching the partitions of
+// the given disk name, which are suffixed with a "p" when the disk name
+// ends with a digit.
+func blockDevicesPartitionsGlob(disk string) string {
+	if last := disk[len(disk)-1]; last >= '0' && last <= '9' {
+		return disk + "p[0-9]*"
+	}
+	return disk + "[0-9]*"
+}
+
+func blockDevicesConnectedPlugAppArmor(slot *interfaces.Slot) string {
+	device, partitions, err := blockDevicesAttrs(slot)
+	if err != nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`
+# Description: Allow raw access to the block device named by the slot. This
+# gives full control over the data on the device and should only be used
+# with trusted apps.
+`)
+	fmt.Fprintf(&buf, "%s rw,\n", device)
+	if partitions {
+		fmt.Fprintf(&buf, "%s rw,\n", blockDevicesPartitionsGlob(device))
+	}
+	buf.WriteString("\n/run/udev/data/b[0-9]*:[0-9]* r,\n")
+	return buf.String()
+}
+
+func blockDevicesConnectedPlugUDev(slot *interfaces.Slot) string {
+	device, partitions, err := blockDevicesAttrs(slot)
+	if err != nil {
+		return ""
+	}
+	name := filepath.Base(device)
+	rules := fmt.Sprintf(`SUBSYSTEM=="block", KERNEL=="%s", TAG+="###CONNECTED_SECURITY_TAGS###"`, name)
+	if partitions {
+		rules += fmt.Sprintf("\n"+`SUBSYSTEM=="block", KERNEL=="%s", TAG+="###CONNECTED_SECURITY_TAGS###"`, blockDevicesPartitionsGlob(name))
+	}
+	return rules
+}
+
+func init() {
+	var iface *commonInterface
+	iface = &commonInterface{
+		name:                 "block-devices",
+		summary:              blockDevicesSummary,
+		baseDeclarationSlots: blockDevicesBaseDeclarationSlots,
+		sanitizeSlot: func(slot *interfaces.Slot) error {
+			if err := sanitizeSlotReservedForOSOrGadget(iface, slot); err != nil {
+				return err
+			}
+			_, _, err := blockDevicesAttrs(slot)
+			return err
+		},
+		connectedPlugAppArmorForSlot: blockDevicesConnectedPlugAppArmor,
+		connectedPlugUDevForSlot:     blockDevicesConnectedPlugUDev,
+	}
+	registerIface(iface)
+}
@@ -0,0 +1,210 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type BlockDevicesInterfaceSuite struct {
+	iface interfaces.Interface
+
+	// Core Snap
+	diskSlot       *interfaces.Slot
+	partitionsSlot *interfaces.Slot
+	nvmeSlot       *interfaces.Slot
+	partitionSlot  *interfaces.Slot
+
+	// Gadget Snap
+	gadgetSlot *interfaces.Slot
+
+	// App Snap
+	appSlot *interfaces.Slot
+
+	// Consuming Snap
+	plug *interfaces.Plug
+}
+
+var _ = Suite(&BlockDevicesInterfaceSuite{
+	iface: builtin.MustInterface("block-devices"),
+})
+
+func (s *BlockDevicesInterfaceSuite) SetUpTest(c *C) {
+	coreSnapInfo := snaptest.MockInfo(c, `
+name: core
+type: os
+slots:
+    disk:
+        interface: block-devices
+        device: /dev/sdb
+    disk-partitions:
+        interface: block-devices
+        device: /dev/sdb
+        partitions: true
+    nvme-partitions:
+        interface: block-devices
+        device: /dev/nvme0n1
+        partitions: true
+    partition:
+        interface: block-devices
+        device: /dev/mmcblk0p2
+`, nil)
+	s.diskSlot = &interfaces.Slot{SlotInfo: coreSnapInfo.Slots["disk"]}
+	s.partitionsSlot = &interfaces.Slot{SlotInfo: coreSnapInfo.Slots["disk-partitions"]}
+	s.nvmeSlot = &interfaces.Slot{SlotInfo: coreSnapInfo.Slots["nvme-partitions"]}
+	s.partitionSlot = &interfaces.Slot{SlotInfo: coreSnapInfo.Slots["partition"]}
+
+	gadgetSnapInfo := snaptest.MockInfo(c, `
+name: some-device
+type: gadget
+slots:
+    data-disk:
+        interface: block-devices
+        device: /dev/mmcblk1
+        partitions: true
+`, nil)
+	s.gadgetSlot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["data-disk"]}
+
+	appSnapInfo := snaptest.MockInfo(c, `
+name: some-app
+slots:
+    disk:
+        interface: block-devices
+        device: /dev/sdb
+`, nil)
+	s.appSlot = &interfaces.Slot{SlotInfo: appSnapInfo.Slots["disk"]}
+
+	consumingSnapInfo := snaptest.MockInfo(c, `
+name: client-snap
+apps:
+    app-accessing-device:
+        command: foo
+        plugs: [block-devices]
+`, nil)
+	s.plug = &interfaces.Plug{PlugInfo: consumingSnapInfo.Plugs["block-devices"]}
+}
+
+func (s *BlockDevicesInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "block-devices")
+}
+
+func (s *BlockDevicesInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(s.diskSlot.Sanitize(s.iface), IsNil)
+	c.Assert(s.partitionsSlot.Sanitize(s.iface), IsNil)
+	c.Assert(s.nvmeSlot.Sanitize(s.iface), IsNil)
+	c.Assert(s.partitionSlot.Sanitize(s.iface), IsNil)
+	c.Assert(s.gadgetSlot.Sanitize(s.iface), IsNil)
+
+	c.Assert(s.appSlot.Sanitize(s.iface), ErrorMatches,
+		"block-devices slots are reserved for the core and gadget snaps")
+}
+
+func (s *BlockDevicesInterfaceSuite) TestSanitizeSlotBadAttrs(c *C) {
+	for _, t := range []struct {
+		attrs map[string]interface{}
+		err   string
+	}{
+		{nil, "block-devices slot must have a device attribute"},
+		{map[string]interface{}{"device": ""}, "block-devices slot must have a device attribute"},
+		{map[string]interface{}{"device": 1}, "block-devices slot must have a device attribute"},
+		{map[string]interface{}{"device": "/dev/sdb", "partitions": "yes"}, "block-devices partitions attribute must be a boolean"},
+		{map[string]interface{}{"device": "/dev/../dev/sdb"}, `block-devices device attribute must be a clean path: "/dev/../dev/sdb"`},
+		{map[string]interface{}{"device": "/dev/sd*"}, `block-devices device attribute must name a single block device: "/dev/sd\*"`},
+		{map[string]interface{}{"device": "/dev/sdb[0-9]"}, `block-devices device attribute must name a single block device: .*`},
+		{map[string]interface{}{"device": "/dev/mmcblk0p*"}, `block-devices device attribute must name a single block device: .*`},
+		{map[string]interface{}{"device": "/dev/tty1"}, `block-devices device attribute must name a single block device: "/dev/tty1"`},
+		{map[string]interface{}{"device": "/dev/sdb1", "partitions": true}, `block-devices partitions attribute needs a whole disk device: "/dev/sdb1"`},
+	} {
+		slot := &interfaces.Slot{SlotInfo: &snap.SlotInfo{
+			Snap:      s.diskSlot.Snap,
+			Name:      "disk",
+			Interface: "block-devices",
+			Attrs:     t.attrs,
+		}}
+		c.Check(slot.Sanitize(s.iface), ErrorMatches, t.err, Commentf("%v", t.attrs))
+	}
+}
+
+func (s *BlockDevicesInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(s.plug.Sanitize(s.iface), IsNil)
+}
+
+func (s *BlockDevicesInterfaceSuite) TestAppArmorSpec(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.diskSlot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.client-snap.app-accessing-device"})
+	snippet := spec.SnippetForTag("snap.client-snap.app-accessing-device")
+	c.Check(snippet, testutil.Contains, "\n/dev/sdb rw,\n")
+	c.Check(snippet, Not(testutil.Contains), "/dev/sdb[0-9]* rw,")
+
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.partitionsSlot, nil), IsNil)
+	snippet = spec.SnippetForTag("snap.client-snap.app-accessing-device")
+	c.Check(snippet, testutil.Contains, "\n/dev/sdb rw,\n/dev/sdb[0-9]* rw,\n")
+
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.nvmeSlot, nil), IsNil)
+	snippet = spec.SnippetForTag("snap.client-snap.app-accessing-device")
+	c.Check(snippet, testutil.Contains, "\n/dev/nvme0n1 rw,\n/dev/nvme0n1p[0-9]* rw,\n")
+
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.gadgetSlot, nil), IsNil)
+	snippet = spec.SnippetForTag("snap.client-snap.app-accessing-device")
+	c.Check(snippet, testutil.Contains, "\n/dev/mmcblk1 rw,\n/dev/mmcblk1p[0-9]* rw,\n")
+}
+
+func (s *BlockDevicesInterfaceSuite) TestUDevSpec(c *C) {
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.diskSlot, nil), IsNil)
+	c.Assert(spec.Snippets(), DeepEquals, []string{
+		`SUBSYSTEM=="block", KERNEL=="sdb", TAG+="snap_client-snap_app-accessing-device"`,
+	})
+
+	spec = &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.partitionsSlot, nil), IsNil)
+	c.Assert(spec.Snippets(), DeepEquals, []string{`SUBSYSTEM=="block", KERNEL=="sdb", TAG+="snap_client-snap_app-accessing-device"
+SUBSYSTEM=="block", KERNEL=="sdb[0-9]*", TAG+="snap_client-snap_app-accessing-device"`})
+
+	spec = &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.partitionSlot, nil), IsNil)
+	c.Assert(spec.Snippets(), DeepEquals, []string{
+		`SUBSYSTEM=="block", KERNEL=="mmcblk0p2", TAG+="snap_client-snap_app-accessing-device"`,
+	})
+}
+
+func (s *BlockDevicesInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, false)
+	c.Assert(si.Summary, Equals, "allows raw access to the block device named by the slot")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "block-devices")
+}
+
+func (s *BlockDevicesInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
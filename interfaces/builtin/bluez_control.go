@@ -0,0 +1,100 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package builtin
+
+const bluezControlSummary = `allows managing Bluetooth through the BlueZ service`
+
+const bluezControlBaseDeclarationSlots = `
+  bluez-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const bluezControlConnectedPlugAppArmor = `
+# Description: Can manage Bluetooth adapters and devices via the BlueZ D-Bus
+# service provided by the system, and register agents and profiles with it.
+# See http://git.kernel.org/cgit/bluetooth/bluez.git/tree/doc
+
+#include <abstractions/dbus-strict>
+
+# Introspection of org.bluez
+dbus (send)
+    bus=system
+    path=/{,org/bluez{,/**}}
+    interface=org.freedesktop.DBus.Introspectable
+    member=Introspect
+    peer=(name=org.bluez, label=unconfined),
+
+# Enumerate adapters and devices
+dbus (send)
+    bus=system
+    path=/
+    interface=org.freedesktop.DBus.ObjectManager
+    member=GetManagedObjects
+    peer=(name=org.bluez, label=unconfined),
+
+dbus (receive)
+    bus=system
+    path=/
+    interface=org.freedesktop.DBus.ObjectManager
+    member=Interfaces{Added,Removed}
+    peer=(label=unconfined),
+
+# Read and set adapter and device properties
+dbus (send)
+    bus=system
+    path=/org/bluez{,/**}
+    interface=org.freedesktop.DBus.Properties
+    member={Get,GetAll,Set}
+    peer=(name=org.bluez, label=unconfined),
+
+dbus (receive)
+    bus=system
+    path=/org/bluez{,/**}
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged
+    peer=(label=unconfined),
+
+# Adapter, device and agent/profile manager methods
+dbus (send)
+    bus=system
+    path=/org/bluez{,/**}
+    interface=org.bluez.{Adapter1,Device1,AgentManager1,ProfileManager1}
+    peer=(name=org.bluez, label=unconfined),
+
+# Agents registered with bluez get called back by it
+dbus (receive)
+    bus=system
+    interface=org.bluez.{Agent1,Profile1}
+    peer=(label=unconfined),
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "bluez-control",
+		summary:               bluezControlSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  bluezControlBaseDeclarationSlots,
+		connectedPlugAppArmor: bluezControlConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
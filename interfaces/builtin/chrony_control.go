@@ -0,0 +1,63 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const chronyControlSummary = `allows managing the chrony NTP daemon`
+
+const chronyControlBaseDeclarationSlots = `
+  chrony-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const chronyControlConnectedPlugAppArmor = `
+# Description: Can manage the chrony NTP daemon, both through its
+# configuration and at runtime through its control socket.
+
+/etc/chrony/chrony.conf rw,
+
+# chronyc talks to chronyd over a datagram unix socket, binding its
+# own socket next to it. The sockets are pathname sockets so the file
+# rules are what limits them to /run/chrony.
+/run/chrony/ r,
+/run/chrony/chronyd.sock rw,
+/run/chrony/chronyc.*.sock rw,
+unix (bind, connect, send, receive) type=dgram,
+
+# chronyc falls back to the UDP command port on localhost
+network inet dgram,
+network inet6 dgram,
+
+/usr/bin/chronyc ixr,
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "chrony-control",
+		summary:               chronyControlSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  chronyControlBaseDeclarationSlots,
+		connectedPlugAppArmor: chronyControlConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
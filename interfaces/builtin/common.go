@@ -26,6 +26,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/kmod"
+	"github.com/snapcore/snapd/interfaces/mount"
 	"github.com/snapcore/snapd/interfaces/seccomp"
 	"github.com/snapcore/snapd/interfaces/udev"
 )
@@ -48,11 +49,52 @@ type commonInterface struct {
 	baseDeclarationSlots string
 
 	connectedPlugAppArmor  string
+	connectedSlotAppArmor  string
+	permanentSlotAppArmor  string
 	connectedPlugSecComp   string
 	connectedPlugUDev      string
+	connectedPlugMount     []mount.Entry
 	reservedForOS          bool
 	rejectAutoConnectPairs bool
 
+	// autoConnect, if set, decides whether the given plug and slot
+	// should be auto-connected, for example by comparing the
+	// identities of their snaps. Declaration-based checks still
+	// apply on top of it.
+	autoConnect func(plug *interfaces.Plug, slot *interfaces.Slot) bool
+
+	// defaultPlugAttrs and defaultSlotAttrs, if set, hold the values
+	// of the attributes a plug or slot gets when the snap does not
+	// set them itself, applied before sanitizePlug and sanitizeSlot.
+	defaultPlugAttrs map[string]interface{}
+	defaultSlotAttrs map[string]interface{}
+
+	// sanitizePlug and sanitizeSlot, if set, check the attributes
+	// of a plug or slot, an error blocks them.
+	sanitizePlug func(plug *interfaces.Plug) error
+	sanitizeSlot func(slot *interfaces.Slot) error
+
+	// disconnectPlug and disconnectSlot, if set, are invoked after
+	// a plug and slot got disconnected, to clean up anything set up
+	// for the connection outside of the security backends.
+	disconnectPlug func(plug *interfaces.Plug, slot *interfaces.Slot) error
+	disconnectSlot func(plug *interfaces.Plug, slot *interfaces.Slot) error
+
+	// connectedPlugUDevForSlot, if set, returns the connected plug
+	// udev rules derived from the attributes of the slot, used
+	// instead of connectedPlugUDev.
+	connectedPlugUDevForSlot func(slot *interfaces.Slot) string
+
+	// connectedPlugAppArmorForSlot, if set, returns the connected
+	// plug apparmor snippet derived from the attributes of the slot,
+	// used instead of connectedPlugAppArmor.
+	connectedPlugAppArmorForSlot func(slot *interfaces.Slot) string
+
+	// connectedPlugAppArmorForPlug, if set, returns the connected
+	// plug apparmor snippet derived from the attributes of the plug,
+	// used instead of connectedPlugAppArmor.
+	connectedPlugAppArmorForPlug func(plug *interfaces.Plug) string
+
 	connectedPlugKModModules []string
 	connectedSlotKModModules []string
 	permanentPlugKModModules []string
@@ -76,20 +118,93 @@ func (iface *commonInterface) StaticInfo() interfaces.StaticInfo {
 	}
 }
 
+// applyDefaultAttrs sets the attributes in defaults not set in attrs
+// yet, returning the possibly allocated attrs.
+func applyDefaultAttrs(attrs, defaults map[string]interface{}) map[string]interface{} {
+	for name, value := range defaults {
+		if _, ok := attrs[name]; ok {
+			continue
+		}
+		if attrs == nil {
+			attrs = make(map[string]interface{}, len(defaults))
+		}
+		attrs[name] = value
+	}
+	return attrs
+}
+
+// SanitizePlug checks and possibly modifies a plug.
+func (iface *commonInterface) SanitizePlug(plug *interfaces.Plug) error {
+	plug.Attrs = applyDefaultAttrs(plug.Attrs, iface.defaultPlugAttrs)
+	if iface.sanitizePlug != nil {
+		return iface.sanitizePlug(plug)
+	}
+	return nil
+}
+
 // SanitizeSlot checks and possibly modifies a slot.
 //
+// isReservedForOS returns whether only the OS snap can have slots of
+// the interface.
+func (iface *commonInterface) isReservedForOS() bool {
+	return iface.reservedForOS
+}
+
 // If the reservedForOS flag is set then only slots on core snap
 // are allowed.
 func (iface *commonInterface) SanitizeSlot(slot *interfaces.Slot) error {
 	if iface.reservedForOS {
-		return sanitizeSlotReservedForOS(iface, slot)
+		if err := sanitizeSlotReservedForOS(iface, slot); err != nil {
+			return err
+		}
+	}
+	slot.Attrs = applyDefaultAttrs(slot.Attrs, iface.defaultSlotAttrs)
+	if iface.sanitizeSlot != nil {
+		return iface.sanitizeSlot(slot)
+	}
+	return nil
+}
+
+// DisconnectPlug cleans up after the plug got disconnected.
+func (iface *commonInterface) DisconnectPlug(plug *interfaces.Plug, slot *interfaces.Slot) error {
+	if iface.disconnectPlug != nil {
+		return iface.disconnectPlug(plug, slot)
+	}
+	return nil
+}
+
+// DisconnectSlot cleans up after the slot got disconnected.
+func (iface *commonInterface) DisconnectSlot(plug *interfaces.Plug, slot *interfaces.Slot) error {
+	if iface.disconnectSlot != nil {
+		return iface.disconnectSlot(plug, slot)
 	}
 	return nil
 }
 
 func (iface *commonInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.Plug, plugAttrs map[string]interface{}, slot *interfaces.Slot, slotAttrs map[string]interface{}) error {
-	if iface.connectedPlugAppArmor != "" {
-		spec.AddSnippet(iface.connectedPlugAppArmor)
+	snippet := iface.connectedPlugAppArmor
+	if iface.connectedPlugAppArmorForSlot != nil {
+		snippet = iface.connectedPlugAppArmorForSlot(slot)
+	}
+	if iface.connectedPlugAppArmorForPlug != nil {
+		snippet = iface.connectedPlugAppArmorForPlug(plug)
+	}
+	if snippet != "" {
+		spec.AddSnippet(snippet)
+	}
+	return nil
+}
+
+func (iface *commonInterface) AppArmorConnectedSlot(spec *apparmor.Specification, plug *interfaces.Plug, plugAttrs map[string]interface{}, slot *interfaces.Slot, slotAttrs map[string]interface{}) error {
+	if iface.connectedSlotAppArmor != "" {
+		spec.AddSnippet(iface.connectedSlotAppArmor)
+	}
+	return nil
+}
+
+func (iface *commonInterface) AppArmorPermanentSlot(spec *apparmor.Specification, slot *interfaces.Slot) error {
+	if iface.permanentSlotAppArmor != "" {
+		spec.AddSnippet(iface.permanentSlotAppArmor)
 	}
 	return nil
 }
@@ -98,9 +213,16 @@ func (iface *commonInterface) AppArmorConnectedPlug(spec *apparmor.Specification
 // auto-connected assuming they will be an unambiguous connection
 // candidate and declaration-based checks allow.
 //
-// By default we allow what declarations allowed.
-func (iface *commonInterface) AutoConnect(*interfaces.Plug, *interfaces.Slot) bool {
-	return !iface.rejectAutoConnectPairs
+// By default we allow what declarations allowed, unless the
+// interface has its own autoConnect predicate.
+func (iface *commonInterface) AutoConnect(plug *interfaces.Plug, slot *interfaces.Slot) bool {
+	if iface.rejectAutoConnectPairs {
+		return false
+	}
+	if iface.autoConnect != nil {
+		return iface.autoConnect(plug, slot)
+	}
+	return true
 }
 
 func (iface *commonInterface) KModConnectedPlug(spec *kmod.Specification, plug *interfaces.Plug, plugAttrs map[string]interface{}, slot *interfaces.Slot, slotAttrs map[string]interface{}) error {
@@ -139,6 +261,15 @@ func (iface *commonInterface) KModPermanentSlot(spec *kmod.Specification, slot *
 	return nil
 }
 
+func (iface *commonInterface) MountConnectedPlug(spec *mount.Specification, plug *interfaces.Plug, plugAttrs map[string]interface{}, slot *interfaces.Slot, slotAttrs map[string]interface{}) error {
+	for _, e := range iface.connectedPlugMount {
+		if err := spec.AddMountEntry(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (iface *commonInterface) SecCompConnectedPlug(spec *seccomp.Specification, plug *interfaces.Plug, plugAttrs map[string]interface{}, slot *interfaces.Slot, slotAttrs map[string]interface{}) error {
 	if iface.connectedPlugSecComp != "" {
 		spec.AddSnippet(iface.connectedPlugSecComp)
@@ -148,10 +279,14 @@ func (iface *commonInterface) SecCompConnectedPlug(spec *seccomp.Specification,
 
 func (iface *commonInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.Plug, plugAttrs map[string]interface{}, slot *interfaces.Slot, slotAttrs map[string]interface{}) error {
 	old := "###CONNECTED_SECURITY_TAGS###"
-	if iface.connectedPlugUDev != "" {
+	rules := iface.connectedPlugUDev
+	if iface.connectedPlugUDevForSlot != nil {
+		rules = iface.connectedPlugUDevForSlot(slot)
+	}
+	if rules != "" {
 		for appName := range plug.Apps {
 			tag := udevSnapSecurityName(plug.Snap.Name(), appName)
-			snippet := strings.Replace(iface.connectedPlugUDev, old, tag, -1)
+			snippet := strings.Replace(rules, old, tag, -1)
 			spec.AddSnippet(snippet)
 		}
 	}
@@ -20,8 +20,15 @@
 package builtin
 
 import (
+	"fmt"
+	"path/filepath"
+
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/interfaces/seccomp"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -59,6 +66,20 @@ slots:
 		`KERNEL="foo", TAG+="snap_consumer_app-c"`,
 	})
 
+	// or derive them from the slot
+	iface = &commonInterface{
+		name: "common",
+		connectedPlugUDevForSlot: func(slot *interfaces.Slot) string {
+			return fmt.Sprintf(`KERNEL="%s", TAG+="###CONNECTED_SECURITY_TAGS###"`, slot.Snap.Name())
+		},
+	}
+	spec = &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.Snippets(), DeepEquals, []string{
+		`KERNEL="producer", TAG+="snap_consumer_app-a"`,
+		`KERNEL="producer", TAG+="snap_consumer_app-c"`,
+	})
+
 	// connected plug udev rules are optional
 	iface = &commonInterface{
 		name: "common",
@@ -68,6 +89,313 @@ slots:
 	c.Assert(spec.Snippets(), HasLen, 0)
 }
 
+func (s *commonIfaceSuite) TestSanitize(c *C) {
+	plug := MockPlug(c, `
+name: consumer
+plugs:
+  common:
+    path: relative/path
+`, nil, "common")
+	slot := MockSlot(c, `
+name: producer
+slots:
+  common:
+    path: /absolute/path
+`, nil, "common")
+
+	// attributes are not checked by default
+	iface := &commonInterface{name: "common"}
+	c.Check(plug.Sanitize(iface), IsNil)
+	c.Check(slot.Sanitize(iface), IsNil)
+
+	// but an interface can check them
+	checkPath := func(attrs map[string]interface{}) error {
+		path, ok := attrs["path"].(string)
+		if !ok || !filepath.IsAbs(path) {
+			return fmt.Errorf("common path must be absolute")
+		}
+		return nil
+	}
+	iface = &commonInterface{
+		name: "common",
+		sanitizePlug: func(plug *interfaces.Plug) error {
+			return checkPath(plug.Attrs)
+		},
+		sanitizeSlot: func(slot *interfaces.Slot) error {
+			return checkPath(slot.Attrs)
+		},
+	}
+	c.Check(plug.Sanitize(iface), ErrorMatches, "common path must be absolute")
+	c.Check(slot.Sanitize(iface), IsNil)
+}
+
+func (s *commonIfaceSuite) TestSanitizeDefaultAttrs(c *C) {
+	plug := MockPlug(c, `
+name: consumer
+plugs:
+  common:
+    mode: rw
+`, nil, "common")
+	slot := MockSlot(c, `
+name: producer
+slots:
+  common:
+`, nil, "common")
+
+	iface := &commonInterface{
+		name:             "common",
+		defaultPlugAttrs: map[string]interface{}{"mode": "r", "count": int64(1)},
+		defaultSlotAttrs: map[string]interface{}{"mode": "r"},
+		sanitizePlug: func(plug *interfaces.Plug) error {
+			// defaults are applied before checking
+			if _, ok := plug.Attrs["count"]; !ok {
+				return fmt.Errorf("common plug has no count")
+			}
+			return nil
+		},
+	}
+	c.Assert(plug.Sanitize(iface), IsNil)
+	c.Assert(slot.Sanitize(iface), IsNil)
+
+	// the values set by the snap win
+	c.Check(plug.Attrs, DeepEquals, map[string]interface{}{"mode": "rw", "count": int64(1)})
+	c.Check(slot.Attrs, DeepEquals, map[string]interface{}{"mode": "r"})
+
+	// the defaults themselves are left alone
+	c.Check(iface.defaultPlugAttrs, DeepEquals, map[string]interface{}{"mode": "r", "count": int64(1)})
+}
+
+func (s *commonIfaceSuite) TestDisconnect(c *C) {
+	plug := MockPlug(c, `
+name: consumer
+plugs:
+  common:
+`, nil, "common")
+	slot := MockSlot(c, `
+name: producer
+slots:
+  common:
+`, nil, "common")
+
+	// nothing to clean up by default
+	iface := &commonInterface{name: "common"}
+	c.Check(iface.DisconnectPlug(plug, slot), IsNil)
+	c.Check(iface.DisconnectSlot(plug, slot), IsNil)
+
+	// but an interface can clean up after either side
+	var calls []string
+	iface = &commonInterface{
+		name: "common",
+		disconnectPlug: func(plug *interfaces.Plug, slot *interfaces.Slot) error {
+			calls = append(calls, "plug "+plug.Snap.Name()+" "+slot.Snap.Name())
+			return nil
+		},
+		disconnectSlot: func(plug *interfaces.Plug, slot *interfaces.Slot) error {
+			calls = append(calls, "slot "+plug.Snap.Name()+" "+slot.Snap.Name())
+			return fmt.Errorf("cannot remove common file")
+		},
+	}
+	c.Check(iface.DisconnectPlug(plug, slot), IsNil)
+	c.Check(iface.DisconnectSlot(plug, slot), ErrorMatches, "cannot remove common file")
+	c.Check(calls, DeepEquals, []string{"plug consumer producer", "slot consumer producer"})
+}
+
+func (s *commonIfaceSuite) TestAutoConnect(c *C) {
+	plug := MockPlug(c, `
+name: consumer
+plugs:
+  common:
+`, nil, "common")
+	slot := MockSlot(c, `
+name: producer
+slots:
+  common:
+`, nil, "common")
+
+	// by default what declarations allow is allowed
+	iface := &commonInterface{name: "common"}
+	c.Check(iface.AutoConnect(plug, slot), Equals, true)
+
+	iface = &commonInterface{name: "common", rejectAutoConnectPairs: true}
+	c.Check(iface.AutoConnect(plug, slot), Equals, false)
+
+	// but an interface can compare the snaps itself
+	iface = &commonInterface{
+		name: "common",
+		autoConnect: func(plug *interfaces.Plug, slot *interfaces.Slot) bool {
+			return plug.Snap.PublisherID == slot.Snap.PublisherID
+		},
+	}
+	plug.Snap.PublisherID = "publisher"
+	slot.Snap.PublisherID = "other-publisher"
+	c.Check(iface.AutoConnect(plug, slot), Equals, false)
+	slot.Snap.PublisherID = "publisher"
+	c.Check(iface.AutoConnect(plug, slot), Equals, true)
+}
+
+func (s *commonIfaceSuite) TestAppArmorSlotSpec(c *C) {
+	plug := MockPlug(c, `
+name: consumer
+apps:
+  app:
+    plugs: [common]
+`, nil, "common")
+	slot := MockSlot(c, `
+name: producer
+apps:
+  app:
+    slots: [common]
+`, nil, "common")
+
+	// common interface can define slot side apparmor rules
+	iface := &commonInterface{
+		name:                  "common",
+		connectedSlotAppArmor: "/connected r,\n",
+		permanentSlotAppArmor: "/permanent r,\n",
+	}
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedSlot(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.producer.app"})
+	c.Assert(spec.SnippetForTag("snap.producer.app"), Equals, "/connected r,\n")
+
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddPermanentSlot(iface, slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.producer.app"})
+	c.Assert(spec.SnippetForTag("snap.producer.app"), Equals, "/permanent r,\n")
+
+	// slot side apparmor rules are optional
+	iface = &commonInterface{
+		name: "common",
+	}
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedSlot(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.AddPermanentSlot(iface, slot), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 0)
+}
+
+func (s *commonIfaceSuite) TestAppArmorPlugSpec(c *C) {
+	plug := MockPlug(c, `
+name: consumer
+apps:
+  app:
+    plugs: [common]
+`, nil, "common")
+	slot := MockSlot(c, `
+name: producer
+slots:
+  common:
+`, nil, "common")
+
+	// common interface can define connected plug apparmor rules
+	iface := &commonInterface{
+		name:                  "common",
+		connectedPlugAppArmor: "/connected r,\n",
+	}
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	c.Assert(spec.SnippetForTag("snap.consumer.app"), Equals, "/connected r,\n")
+
+	// or derive them from the slot
+	iface = &commonInterface{
+		name: "common",
+		connectedPlugAppArmorForSlot: func(slot *interfaces.Slot) string {
+			return fmt.Sprintf("/%s r,\n", slot.Snap.Name())
+		},
+	}
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.SnippetForTag("snap.consumer.app"), Equals, "/producer r,\n")
+
+	// or from the plug
+	iface = &commonInterface{
+		name: "common",
+		connectedPlugAppArmorForPlug: func(plug *interfaces.Plug) string {
+			return fmt.Sprintf("/%s r,\n", plug.Snap.Name())
+		},
+	}
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.SnippetForTag("snap.consumer.app"), Equals, "/consumer r,\n")
+
+	// connected plug apparmor rules are optional
+	iface = &commonInterface{
+		name: "common",
+	}
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 0)
+}
+
+func (s *commonIfaceSuite) TestMountSpec(c *C) {
+	plug := MockPlug(c, `
+name: consumer
+apps:
+  app:
+    plugs: [common]
+`, nil, "common")
+	slot := MockSlot(c, `
+name: producer
+slots:
+  common:
+`, nil, "common")
+
+	// common interface can define connected plug mount entries
+	entry := mount.Entry{
+		Name:    "/var/lib/snapd/hostfs/usr/share/common",
+		Dir:     "/usr/share/common",
+		Options: []string{"bind", "ro"},
+	}
+	iface := &commonInterface{
+		name:               "common",
+		connectedPlugMount: []mount.Entry{entry},
+	}
+	spec := &mount.Specification{}
+	c.Assert(spec.AddConnectedPlug(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.MountEntries(), DeepEquals, []mount.Entry{entry})
+
+	// connected plug mount entries are optional
+	iface = &commonInterface{
+		name: "common",
+	}
+	spec = &mount.Specification{}
+	c.Assert(spec.AddConnectedPlug(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.MountEntries(), HasLen, 0)
+}
+
+func (s *commonIfaceSuite) TestSecCompSpec(c *C) {
+	plug := MockPlug(c, `
+name: consumer
+apps:
+  app-a:
+    plugs: [common]
+  app-b:
+`, nil, "common")
+	slot := MockSlot(c, `
+name: producer
+slots:
+  common:
+`, nil, "common")
+
+	// common interface can define connected plug seccomp rules
+	iface := &commonInterface{
+		name:                 "common",
+		connectedPlugSecComp: "bind",
+	}
+	spec := &seccomp.Specification{}
+	c.Assert(spec.AddConnectedPlug(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app-a"})
+	c.Assert(spec.SnippetForTag("snap.consumer.app-a"), Equals, "bind\n")
+
+	// connected plug seccomp rules are optional
+	iface = &commonInterface{
+		name: "common",
+	}
+	spec = &seccomp.Specification{}
+	c.Assert(spec.AddConnectedPlug(iface, plug, nil, slot, nil), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 0)
+}
+
 // MockEvalSymlinks replaces the path/filepath.EvalSymlinks function used inside the caps package.
 func MockEvalSymlinks(test *testutil.BaseTest, fn func(string) (string, error)) {
 	orig := evalSymlinks
@@ -76,3 +404,21 @@ func MockEvalSymlinks(test *testutil.BaseTest, fn func(string) (string, error))
 		evalSymlinks = orig
 	})
 }
+
+func (s *commonIfaceSuite) TestStaticInfo(c *C) {
+	iface := &commonInterface{
+		name:                 "common",
+		summary:              "summary",
+		baseDeclarationPlugs: "plugs",
+		baseDeclarationSlots: "slots",
+	}
+	si := interfaces.StaticInfoOf(iface)
+	c.Check(si.Summary, Equals, "summary")
+	c.Check(si.BaseDeclarationPlugs, Equals, "plugs")
+	c.Check(si.BaseDeclarationSlots, Equals, "slots")
+
+	// base declaration plug rules are optional
+	iface = &commonInterface{name: "common"}
+	si = interfaces.StaticInfoOf(iface)
+	c.Check(si.BaseDeclarationPlugs, Equals, "")
+}
@@ -0,0 +1,61 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const cpuControlSummary = `allows setting CPU frequency governors and bringing CPUs online or offline`
+
+const cpuControlBaseDeclarationSlots = `
+  cpu-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const cpuControlConnectedPlugAppArmor = `
+# Description: Can manage the CPU frequency scaling and the online state of
+# CPUs. This is reserved because it allows affecting the performance and power
+# usage of the whole system.
+
+/sys/devices/system/cpu/ r,
+/sys/devices/system/cpu/{online,offline,possible,present} r,
+
+# https://www.kernel.org/doc/Documentation/cputopology.txt
+/sys/devices/system/cpu/cpu[0-9]*/online rw,
+
+# https://www.kernel.org/doc/Documentation/cpu-freq/user-guide.txt
+# The per-CPU cpufreq directories are symlinks to the policies in
+# /sys/devices/system/cpu/cpufreq
+/sys/devices/system/cpu/cpu[0-9]*/cpufreq/ r,
+/sys/devices/system/cpu/cpu[0-9]*/cpufreq/** rw,
+/sys/devices/system/cpu/cpufreq/ r,
+/sys/devices/system/cpu/cpufreq/** rw,
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "cpu-control",
+		summary:               cpuControlSummary,
+		implicitOnCore:        true,
+		baseDeclarationSlots:  cpuControlBaseDeclarationSlots,
+		connectedPlugAppArmor: cpuControlConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
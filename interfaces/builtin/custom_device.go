@@ -0,0 +1,217 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+)
+
+const customDeviceSummary = `allows access to the board specific devices described by the slot`
+
+const customDeviceBaseDeclarationSlots = `
+  custom-device:
+    allow-installation:
+      slot-snap-type:
+        - gadget
+        - core
+    deny-auto-connection: true
+`
+
+// Pattern to match the paths the slot can grant access to, which are
+// device nodes and sysfs entries
+var customDevicePathPattern = regexp.MustCompile(`^/(dev|sys)(/[A-Za-z0-9:@_.+-]+)+$`)
+
+// Pattern to match the values used in the udev rules tagging devices
+var customDeviceUDevValuePattern = regexp.MustCompile(`^[A-Za-z0-9:_.+*?\[\]-]+$`)
+
+// Pattern to match the udev values made only of wildcards, which would
+// tag every device
+var customDeviceUDevWildcardPattern = regexp.MustCompile(`^(\*|\?|\[[^\]]*\])+$`)
+
+// customDeviceUDevTag describes the devices a udev rule tags for the
+// connected plugs.
+type customDeviceUDevTag struct {
+	kernel    string
+	subsystem string
+}
+
+// customDeviceAttrs holds the attributes of a custom-device slot: the
+// paths that can be read, the paths that can also be written and the
+// devices to tag for the plugs besides those of the device nodes.
+type customDeviceAttrs struct {
+	read        []string
+	write       []string
+	udevTagging []customDeviceUDevTag
+}
+
+func customDevicePaths(slot *interfaces.Slot, name string) ([]string, error) {
+	v, ok := slot.Attrs[name]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("custom-device %s attribute must be a list of paths", name)
+	}
+	paths := make([]string, len(list))
+	for i, p := range list {
+		path, ok := p.(string)
+		if !ok {
+			return nil, fmt.Errorf("custom-device %s attribute must be a list of paths", name)
+		}
+		if !filepath.IsAbs(path) || filepath.Clean(path) != path {
+			return nil, fmt.Errorf("custom-device %s path must be clean and absolute: %q", name, path)
+		}
+		if !customDevicePathPattern.MatchString(path) {
+			return nil, fmt.Errorf("custom-device %s path must be in /dev or /sys: %q", name, path)
+		}
+		paths[i] = path
+	}
+	return paths, nil
+}
+
+func customDeviceUDevTagging(slot *interfaces.Slot) ([]customDeviceUDevTag, error) {
+	v, ok := slot.Attrs["udev-tagging"]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("custom-device udev-tagging attribute must be a list of rules")
+	}
+	tags := make([]customDeviceUDevTag, len(list))
+	for i, r := range list {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("custom-device udev-tagging attribute must be a list of rules")
+		}
+		for key, value := range rule {
+			s, ok := value.(string)
+			if !ok || !customDeviceUDevValuePattern.MatchString(s) {
+				return nil, fmt.Errorf("custom-device udev-tagging %s must be a valid udev match: %v", key, value)
+			}
+			switch key {
+			case "kernel":
+				if customDeviceUDevWildcardPattern.MatchString(s) {
+					return nil, fmt.Errorf("custom-device udev-tagging kernel cannot match every device: %v", value)
+				}
+				tags[i].kernel = s
+			case "subsystem":
+				tags[i].subsystem = s
+			default:
+				return nil, fmt.Errorf("custom-device udev-tagging rules cannot match on %q", key)
+			}
+		}
+		if tags[i].kernel == "" {
+			return nil, fmt.Errorf("custom-device udev-tagging rules must match on kernel")
+		}
+	}
+	return tags, nil
+}
+
+// customDeviceSlotAttrs returns the attributes of the slot, checking
+// that they are valid.
+func customDeviceSlotAttrs(slot *interfaces.Slot) (*customDeviceAttrs, error) {
+	read, err := customDevicePaths(slot, "read")
+	if err != nil {
+		return nil, err
+	}
+	write, err := customDevicePaths(slot, "write")
+	if err != nil {
+		return nil, err
+	}
+	if len(read) == 0 && len(write) == 0 {
+		return nil, fmt.Errorf("custom-device slot must have read or write paths")
+	}
+	udevTagging, err := customDeviceUDevTagging(slot)
+	if err != nil {
+		return nil, err
+	}
+	return &customDeviceAttrs{read: read, write: write, udevTagging: udevTagging}, nil
+}
+
+func customDeviceConnectedPlugAppArmor(slot *interfaces.Slot) string {
+	attrs, err := customDeviceSlotAttrs(slot)
+	if err != nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`
+# Description: Allow access to the board specific devices described by the
+# slot of the gadget.
+`)
+	for _, path := range attrs.read {
+		fmt.Fprintf(&buf, "%s r,\n", path)
+	}
+	for _, path := range attrs.write {
+		fmt.Fprintf(&buf, "%s rw,\n", path)
+	}
+	buf.WriteString("\n/run/udev/data/* r,\n")
+	return buf.String()
+}
+
+func customDeviceConnectedPlugUDev(slot *interfaces.Slot) string {
+	attrs, err := customDeviceSlotAttrs(slot)
+	if err != nil {
+		return ""
+	}
+	var rules []string
+	// the device nodes are tagged for the device cgroup to let them
+	// be opened; the kernel name of nested nodes such as /dev/snd/pcmC0D0p
+	// is their last path element, devices named differently by the kernel
+	// have to be listed in udev-tagging
+	for _, path := range append(attrs.read, attrs.write...) {
+		if strings.HasPrefix(path, "/dev/") {
+			rules = append(rules, fmt.Sprintf(`KERNEL=="%s", TAG+="###CONNECTED_SECURITY_TAGS###"`, filepath.Base(path)))
+		}
+	}
+	for _, tag := range attrs.udevTagging {
+		rule := fmt.Sprintf(`KERNEL=="%s", TAG+="###CONNECTED_SECURITY_TAGS###"`, tag.kernel)
+		if tag.subsystem != "" {
+			rule = fmt.Sprintf(`SUBSYSTEM=="%s", `, tag.subsystem) + rule
+		}
+		rules = append(rules, rule)
+	}
+	return strings.Join(rules, "\n")
+}
+
+func init() {
+	iface := &commonInterface{
+		name:                         "custom-device",
+		summary:                      customDeviceSummary,
+		baseDeclarationSlots:         customDeviceBaseDeclarationSlots,
+		connectedPlugAppArmorForSlot: customDeviceConnectedPlugAppArmor,
+		connectedPlugUDevForSlot:     customDeviceConnectedPlugUDev,
+	}
+	iface.sanitizeSlot = func(slot *interfaces.Slot) error {
+		if err := sanitizeSlotReservedForOSOrGadget(iface, slot); err != nil {
+			return err
+		}
+		_, err := customDeviceSlotAttrs(slot)
+		return err
+	}
+	registerIface(iface)
+}
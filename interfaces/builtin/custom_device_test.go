@@ -0,0 +1,207 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type CustomDeviceInterfaceSuite struct {
+	iface interfaces.Interface
+
+	// Gadget Snap
+	slot *interfaces.Slot
+
+	// App Snap
+	appSlot *interfaces.Slot
+
+	// Consuming Snap
+	plug *interfaces.Plug
+}
+
+var _ = Suite(&CustomDeviceInterfaceSuite{
+	iface: builtin.MustInterface("custom-device"),
+})
+
+func (s *CustomDeviceInterfaceSuite) SetUpTest(c *C) {
+	gadgetSnapInfo := snaptest.MockInfo(c, `
+name: my-board
+type: gadget
+slots:
+    leds:
+        interface: custom-device
+        read:
+            - /sys/class/leds/status/max_brightness
+        write:
+            - /dev/board-leds
+            - /sys/class/leds/status/brightness
+        udev-tagging:
+            - kernel: ttyLED[0-9]*
+              subsystem: tty
+`, nil)
+	s.slot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["leds"]}
+
+	appSnapInfo := snaptest.MockInfo(c, `
+name: some-app
+slots:
+    leds:
+        interface: custom-device
+        write: [/dev/board-leds]
+`, nil)
+	s.appSlot = &interfaces.Slot{SlotInfo: appSnapInfo.Slots["leds"]}
+
+	consumingSnapInfo := snaptest.MockInfo(c, `
+name: client-snap
+apps:
+    app-accessing-leds:
+        command: foo
+        plugs: [custom-device]
+`, nil)
+	s.plug = &interfaces.Plug{PlugInfo: consumingSnapInfo.Plugs["custom-device"]}
+}
+
+func (s *CustomDeviceInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "custom-device")
+}
+
+func (s *CustomDeviceInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(s.slot.Sanitize(s.iface), IsNil)
+	c.Assert(s.appSlot.Sanitize(s.iface), ErrorMatches,
+		"custom-device slots are reserved for the core and gadget snaps")
+}
+
+func (s *CustomDeviceInterfaceSuite) TestSanitizeSlotBadAttrs(c *C) {
+	for _, t := range []struct {
+		attrs map[string]interface{}
+		err   string
+	}{
+		{nil, "custom-device slot must have read or write paths"},
+		{map[string]interface{}{"read": []interface{}{}}, "custom-device slot must have read or write paths"},
+		{map[string]interface{}{"read": "/dev/foo"}, "custom-device read attribute must be a list of paths"},
+		{map[string]interface{}{"write": []interface{}{1}}, "custom-device write attribute must be a list of paths"},
+		{map[string]interface{}{"write": []interface{}{"dev/foo"}}, `custom-device write path must be clean and absolute: "dev/foo"`},
+		{map[string]interface{}{"write": []interface{}{"/dev/../etc/shadow"}}, `custom-device write path must be clean and absolute: .*`},
+		{map[string]interface{}{"write": []interface{}{"/dev/foo/"}}, `custom-device write path must be clean and absolute: .*`},
+		{map[string]interface{}{"write": []interface{}{"/dev"}}, `custom-device write path must be in /dev or /sys: "/dev"`},
+		{map[string]interface{}{"read": []interface{}{"/etc/shadow"}}, `custom-device read path must be in /dev or /sys: "/etc/shadow"`},
+		{map[string]interface{}{"read": []interface{}{"/proc/sys/kernel"}}, `custom-device read path must be in /dev or /sys: .*`},
+		{map[string]interface{}{"read": []interface{}{"/dev/*"}}, `custom-device read path must be in /dev or /sys: .*`},
+		{map[string]interface{}{"read": []interface{}{"/dev/foo r,\n/etc/shadow"}}, `(?s)custom-device read path must be in /dev or /sys: .*`},
+		{map[string]interface{}{
+			"write":        []interface{}{"/dev/foo"},
+			"udev-tagging": "foo",
+		}, "custom-device udev-tagging attribute must be a list of rules"},
+		{map[string]interface{}{
+			"write":        []interface{}{"/dev/foo"},
+			"udev-tagging": []interface{}{map[string]interface{}{"subsystem": "tty"}},
+		}, "custom-device udev-tagging rules must match on kernel"},
+		{map[string]interface{}{
+			"write":        []interface{}{"/dev/foo"},
+			"udev-tagging": []interface{}{map[string]interface{}{"kernel": "foo", "attrs": "bar"}},
+		}, `custom-device udev-tagging rules cannot match on "attrs"`},
+		{map[string]interface{}{
+			"write":        []interface{}{"/dev/foo"},
+			"udev-tagging": []interface{}{map[string]interface{}{"kernel": `foo", RUN+="/bin/sh`}},
+		}, `custom-device udev-tagging kernel must be a valid udev match: .*`},
+		{map[string]interface{}{
+			"write":        []interface{}{"/dev/foo"},
+			"udev-tagging": []interface{}{map[string]interface{}{"kernel": "*"}},
+		}, `custom-device udev-tagging kernel cannot match every device: \*`},
+		{map[string]interface{}{
+			"write":        []interface{}{"/dev/foo"},
+			"udev-tagging": []interface{}{map[string]interface{}{"kernel": "?*[a-z]"}},
+		}, `custom-device udev-tagging kernel cannot match every device: .*`},
+	} {
+		slot := &interfaces.Slot{SlotInfo: &snap.SlotInfo{
+			Snap:      s.slot.Snap,
+			Name:      "leds",
+			Interface: "custom-device",
+			Attrs:     t.attrs,
+		}}
+		c.Check(slot.Sanitize(s.iface), ErrorMatches, t.err, Commentf("%v", t.attrs))
+	}
+}
+
+func (s *CustomDeviceInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(s.plug.Sanitize(s.iface), IsNil)
+}
+
+func (s *CustomDeviceInterfaceSuite) TestAppArmorSpec(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.client-snap.app-accessing-leds"})
+	snippet := spec.SnippetForTag("snap.client-snap.app-accessing-leds")
+	c.Check(snippet, testutil.Contains, "\n/sys/class/leds/status/max_brightness r,\n")
+	c.Check(snippet, testutil.Contains, "\n/dev/board-leds rw,\n")
+	c.Check(snippet, testutil.Contains, "\n/sys/class/leds/status/brightness rw,\n")
+}
+
+func (s *CustomDeviceInterfaceSuite) TestUDevSpec(c *C) {
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 1)
+	c.Check(spec.Snippets()[0], Equals, `KERNEL=="board-leds", TAG+="snap_client-snap_app-accessing-leds"
+SUBSYSTEM=="tty", KERNEL=="ttyLED[0-9]*", TAG+="snap_client-snap_app-accessing-leds"`)
+}
+
+func (s *CustomDeviceInterfaceSuite) TestUDevSpecNestedDeviceNodes(c *C) {
+	gadgetSnapInfo := snaptest.MockInfo(c, `
+name: my-board
+type: gadget
+slots:
+    audio:
+        interface: custom-device
+        write:
+            - /dev/snd/pcmC0D0p
+            - /dev/bus/usb/001/002
+        udev-tagging:
+            - kernel: 1-1
+              subsystem: usb
+`, nil)
+	slot := &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["audio"]}
+	c.Assert(slot.Sanitize(s.iface), IsNil)
+
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, slot, nil), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 1)
+	c.Check(spec.Snippets()[0], Equals, `KERNEL=="pcmC0D0p", TAG+="snap_client-snap_app-accessing-leds"
+KERNEL=="002", TAG+="snap_client-snap_app-accessing-leds"
+SUBSYSTEM=="usb", KERNEL=="1-1", TAG+="snap_client-snap_app-accessing-leds"`)
+}
+
+func (s *CustomDeviceInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, false)
+	c.Assert(si.Summary, Equals, "allows access to the board specific devices described by the slot")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "custom-device")
+}
+
+func (s *CustomDeviceInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
@@ -148,6 +148,10 @@ func (iface *desktopInterface) StaticInfo() interfaces.StaticInfo {
 	}
 }
 
+func (iface *desktopInterface) isReservedForOS() bool {
+	return true
+}
+
 func (iface *desktopInterface) SanitizeSlot(slot *interfaces.Slot) error {
 	return sanitizeSlotReservedForOS(iface, slot)
 }
@@ -0,0 +1,90 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const dmCryptSummary = `allows encryption and decryption of block devices with device-mapper`
+
+const dmCryptBaseDeclarationSlots = `
+  dm-crypt:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const dmCryptConnectedPlugAppArmor = `
+# Description: Allow managing encrypted volumes with dm-crypt. This gives
+# privileged access to block devices and the keys protecting them and
+# should only be used with trusted apps.
+
+capability sys_admin,
+capability ipc_lock,
+
+# the device-mapper control node, which all dm ioctls go through
+/dev/mapper/control rw,
+
+# the mapped devices themselves, but not arbitrary block devices, those
+# need the block-devices interface
+/dev/mapper/ r,
+/dev/mapper/* rw,
+/dev/dm-[0-9]* rw,
+
+/{,usr/}{,s}bin/cryptsetup ixr,
+/{,usr/}{,s}bin/dmsetup ixr,
+
+# cryptsetup takes a lock on the devices it operates on
+/run/cryptsetup/ rw,
+/run/cryptsetup/* rwk,
+
+# device-mapper and crypto information
+/sys/devices/virtual/block/dm-[0-9]*/ r,
+/sys/devices/virtual/block/dm-[0-9]*/** r,
+/sys/module/dm_crypt/initstate r,
+@{PROC}/crypto r,
+@{PROC}/devices r,
+@{PROC}/misc r,
+/run/udev/data/b[0-9]*:[0-9]* r,
+`
+
+const dmCryptConnectedPlugSecComp = `
+# Description: Allow managing encrypted volumes with dm-crypt. The dm
+# ioctls on /dev/mapper/control are allowed by the default ioctl rule,
+# cryptsetup also keeps volume keys in the kernel keyring.
+
+add_key
+keyctl
+request_key
+`
+
+const dmCryptConnectedPlugUDev = `SUBSYSTEM=="misc", KERNEL=="device-mapper", TAG+="###CONNECTED_SECURITY_TAGS###"`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "dm-crypt",
+		summary:               dmCryptSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  dmCryptBaseDeclarationSlots,
+		connectedPlugAppArmor: dmCryptConnectedPlugAppArmor,
+		connectedPlugSecComp:  dmCryptConnectedPlugSecComp,
+		connectedPlugUDev:     dmCryptConnectedPlugUDev,
+		reservedForOS:         true,
+	})
+}
@@ -0,0 +1,90 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package builtin
+
+const fwupdControlSummary = `allows driving the fwupd service to update firmware`
+
+const fwupdControlBaseDeclarationSlots = `
+  fwupd-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const fwupdControlConnectedPlugAppArmor = `
+# Description: Can use the fwupd service provided by the host to list devices
+# and apply firmware updates. This gives privileged access to the system.
+
+#include <abstractions/dbus-strict>
+
+# Allow access to the fwupd service
+dbus (receive, send)
+    bus=system
+    path=/{,org/freedesktop/fwupd{,/**}}
+    interface=org.freedesktop.fwupd
+    peer=(label=unconfined),
+
+dbus (send)
+    bus=system
+    path=/{,org/freedesktop/fwupd{,/**}}
+    interface=org.freedesktop.DBus.Properties
+    member=Get{,All}
+    peer=(label=unconfined),
+
+dbus (receive)
+    bus=system
+    path=/{,org/freedesktop/fwupd{,/**}}
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged
+    peer=(label=unconfined),
+
+dbus (send)
+    bus=system
+    path=/{,org/freedesktop/fwupd{,/**}}
+    interface=org.freedesktop.DBus.Introspectable
+    member=Introspect
+    peer=(label=unconfined),
+
+# fwupd state, metadata and cached firmware
+/var/lib/fwupd/ r,
+/var/lib/fwupd/** rw,
+/var/cache/fwupd/ r,
+/var/cache/fwupd/** r,
+
+# Firmware and device information
+/sys/firmware/efi/ r,
+/sys/firmware/efi/fw_platform_size r,
+/sys/firmware/efi/esrt/entries/ r,
+/sys/firmware/efi/esrt/entries/** r,
+/sys/devices/virtual/dmi/id/product_name r,
+/sys/devices/virtual/dmi/id/sys_vendor r,
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "fwupd-control",
+		summary:               fwupdControlSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  fwupdControlBaseDeclarationSlots,
+		connectedPlugAppArmor: fwupdControlConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
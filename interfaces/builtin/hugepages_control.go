@@ -0,0 +1,78 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"github.com/snapcore/snapd/interfaces/mount"
+)
+
+const hugepagesControlSummary = `allows managing huge pages`
+
+const hugepagesControlBaseDeclarationSlots = `
+  hugepages-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const hugepagesControlConnectedPlugAppArmor = `
+# Description: Can set up huge pages and use them through hugetlbfs. This is
+# reserved because reserving huge pages takes memory away from the rest of
+# the system.
+
+# https://www.kernel.org/doc/Documentation/vm/hugetlbpage.txt
+/sys/kernel/mm/hugepages/ r,
+/sys/kernel/mm/hugepages/hugepages-*/ r,
+/sys/kernel/mm/hugepages/hugepages-*/* rw,
+/sys/devices/system/node/node[0-9]*/hugepages/ r,
+/sys/devices/system/node/node[0-9]*/hugepages/hugepages-*/ r,
+/sys/devices/system/node/node[0-9]*/hugepages/hugepages-*/* rw,
+
+@{PROC}/sys/vm/nr_hugepages rw,
+@{PROC}/sys/vm/nr_hugepages_mempolicy rw,
+@{PROC}/sys/vm/nr_overcommit_hugepages rw,
+@{PROC}/meminfo r,
+
+# the hugetlbfs mounted by systemd
+/dev/hugepages/ r,
+/dev/hugepages/** rwk,
+`
+
+// the hugetlbfs is bind mounted explicitly so that it shows up in the
+// snap mount namespace even when /dev is set up before it was mounted
+var hugepagesControlConnectedPlugMount = []mount.Entry{{
+	Name:    "/dev/hugepages",
+	Dir:     "/dev/hugepages",
+	Options: []string{"bind", "rw"},
+}}
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "hugepages-control",
+		summary:               hugepagesControlSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  hugepagesControlBaseDeclarationSlots,
+		connectedPlugAppArmor: hugepagesControlConnectedPlugAppArmor,
+		connectedPlugMount:    hugepagesControlConnectedPlugMount,
+		reservedForOS:         true,
+	})
+}
@@ -19,7 +19,7 @@
 
 package builtin
 
-const ioPortsControlSummary = `allows access to all I/O ports`
+const ioPortsControlSummary = `allows access to all I/O ports, giving raw control over hardware and the ability to crash the system`
 
 const ioPortsControlBaseDeclarationSlots = `
   io-ports-control:
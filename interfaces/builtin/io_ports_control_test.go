@@ -102,7 +102,7 @@ func (s *ioPortsControlInterfaceSuite) TestStaticInfo(c *C) {
 	si := interfaces.StaticInfoOf(s.iface)
 	c.Assert(si.ImplicitOnCore, Equals, true)
 	c.Assert(si.ImplicitOnClassic, Equals, true)
-	c.Assert(si.Summary, Equals, `allows access to all I/O ports`)
+	c.Assert(si.Summary, Equals, `allows access to all I/O ports, giving raw control over hardware and the ability to crash the system`)
 	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "io-ports-control")
 }
 
@@ -0,0 +1,57 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const journalReadSummary = `allows read access to the systemd journal`
+
+const journalReadBaseDeclarationSlots = `
+  journal-read:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const journalReadConnectedPlugAppArmor = `
+# Description: Can read the systemd journal, both persistent and volatile,
+# through the sd-journal API.
+
+/var/log/journal/ r,
+/var/log/journal/** r,
+/run/log/journal/ r,
+/run/log/journal/** r,
+
+# sd-journal uses these to find the journal of the running system and
+# to resolve message catalog entries
+/etc/machine-id r,
+@{PROC}/sys/kernel/random/boot_id r,
+/var/lib/systemd/catalog/database r,
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "journal-read",
+		summary:               journalReadSummary,
+		implicitOnCore:        true,
+		baseDeclarationSlots:  journalReadBaseDeclarationSlots,
+		connectedPlugAppArmor: journalReadConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
@@ -0,0 +1,67 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const kernelCrashDumpControlSummary = `allows configuring kernel crash dumps`
+
+const kernelCrashDumpControlBaseDeclarationSlots = `
+  kernel-crash-dump-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const kernelCrashDumpControlConnectedPlugAppArmor = `
+# Description: Can load a crash kernel with kexec and collect the dump of a
+# crashed kernel. This gives privileged access to the system.
+
+# The memory image of the crashed kernel
+@{PROC}/vmcore r,
+
+# State of the loaded kexec and crash kernels, and the size of the memory
+# reserved for the latter
+/sys/kernel/kexec_loaded r,
+/sys/kernel/kexec_crash_loaded r,
+/sys/kernel/kexec_crash_size rw,
+/sys/kernel/vmcoreinfo r,
+
+# Loading a crash kernel
+capability sys_boot,
+/{,usr/}sbin/kexec ixr,
+`
+
+const kernelCrashDumpControlConnectedPlugSecComp = `
+# Description: Can load a crash kernel with kexec.
+kexec_load
+kexec_file_load
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "kernel-crash-dump-control",
+		summary:               kernelCrashDumpControlSummary,
+		implicitOnCore:        true,
+		baseDeclarationSlots:  kernelCrashDumpControlBaseDeclarationSlots,
+		connectedPlugAppArmor: kernelCrashDumpControlConnectedPlugAppArmor,
+		connectedPlugSecComp:  kernelCrashDumpControlConnectedPlugSecComp,
+		reservedForOS:         true,
+	})
+}
@@ -36,6 +36,15 @@ const kvmConnectedPlugAppArmor = `
 /dev/kvm rw,
 `
 
+const kvmConnectedPlugSecComp = `
+# Description: Allow running virtual machines with kvm. The kvm ioctls on
+# /dev/kvm and the vm and vcpu descriptors it hands out are allowed by the
+# default ioctl rule.
+
+# used by virtual machine monitors for postcopy migration
+userfaultfd
+`
+
 const kvmConnectedPlugUDev = `KERNEL=="kvm", TAG+="###CONNECTED_SECURITY_TAGS###"`
 
 func init() {
@@ -46,6 +55,7 @@ func init() {
 		implicitOnClassic:     true,
 		baseDeclarationSlots:  kvmBaseDeclarationSlots,
 		connectedPlugAppArmor: kvmConnectedPlugAppArmor,
+		connectedPlugSecComp:  kvmConnectedPlugSecComp,
 		connectedPlugUDev:     kvmConnectedPlugUDev,
 		reservedForOS:         true,
 	})
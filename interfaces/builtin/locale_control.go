@@ -32,15 +32,65 @@ const localeControlBaseDeclarationSlots = `
 // http://bazaar.launchpad.net/~ubuntu-security/ubuntu-core-security/trunk/view/head:/data/apparmor/policygroups/ubuntu-core/16.04/locale-control
 const localeControlConnectedPlugAppArmor = `
 # Description: Can manage locales directly separate from 'config ubuntu-core'.
+# Can set the locale and the console keyboard via localed D-Bus interface,
+# Can read all properties of /org/freedesktop/locale1 D-Bus object; see
+# https://www.freedesktop.org/wiki/Software/systemd/localed/
+
+#include <abstractions/dbus-strict>
 
 # TODO: this won't work until snappy exposes this configurability
 /etc/default/locale rw,
+/etc/locale.conf rw,
+
+# Introspection of org.freedesktop.locale1
+dbus (send)
+    bus=system
+    path=/org/freedesktop/locale1
+    interface=org.freedesktop.DBus.Introspectable
+    member=Introspect
+    peer=(label=unconfined),
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/locale1
+    interface=org.freedesktop.locale1
+    member="SetLocale"
+    peer=(label=unconfined),
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/locale1
+    interface=org.freedesktop.locale1
+    member="SetVConsoleKeyboard"
+    peer=(label=unconfined),
+
+# Read all properties from locale1
+dbus (send)
+    bus=system
+    path=/org/freedesktop/locale1
+    interface=org.freedesktop.DBus.Properties
+    member=Get{,All}
+    peer=(label=unconfined),
+
+# Receive locale1 property changed events
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/locale1
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged
+    peer=(label=unconfined),
+
+# As the core snap ships the localectl utility we can also allow
+# clients to use it now that they have access to the relevant
+# D-Bus methods for setting the locale and the console keyboard.
+/usr/bin/localectl{,.real} ixr,
 `
 
 func init() {
 	registerIface(&commonInterface{
 		name:                  "locale-control",
 		summary:               localeControlSummary,
+		implicitOnCore:        true,
 		implicitOnClassic:     true,
 		baseDeclarationSlots:  localeControlBaseDeclarationSlots,
 		connectedPlugAppArmor: localeControlConnectedPlugAppArmor,
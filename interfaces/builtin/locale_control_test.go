@@ -87,6 +87,15 @@ func (s *LocaleControlInterfaceSuite) TestUsedSecuritySystems(c *C) {
 	c.Assert(aasnippets, HasLen, 1)
 	c.Assert(aasnippets["snap.other.app"], HasLen, 1)
 	c.Assert(string(aasnippets["snap.other.app"][0]), testutil.Contains, "/etc/default/locale")
+	c.Assert(string(aasnippets["snap.other.app"][0]), testutil.Contains, "/etc/locale.conf")
+	c.Assert(string(aasnippets["snap.other.app"][0]), testutil.Contains, `member="SetLocale"`)
+	c.Assert(string(aasnippets["snap.other.app"][0]), testutil.Contains, `member="SetVConsoleKeyboard"`)
+}
+
+func (s *LocaleControlInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, true)
+	c.Assert(si.ImplicitOnClassic, Equals, true)
 }
 
 func (s *LocaleControlInterfaceSuite) TestInterfaces(c *C) {
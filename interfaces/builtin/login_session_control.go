@@ -0,0 +1,63 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const loginSessionControlSummary = `allows setup of login sessions and inhibiting shutdown and sleep`
+
+const loginSessionControlBaseDeclarationSlots = `
+  login-session-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+// login-session-control can do everything login-session-observe can
+// plus what is below.
+const loginSessionControlConnectedPlugAppArmor = `
+# Description: Can lock, unlock and terminate login sessions and inhibit
+# shutdown and sleep via logind.
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/login1
+    interface=org.freedesktop.login1.Manager
+    member={ActivateSession,LockSession,UnlockSession,LockSessions,UnlockSessions,TerminateSession,KillSession,TerminateUser,Inhibit,ListInhibitors}
+    peer=(label=unconfined),
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/login1/session/**
+    interface=org.freedesktop.login1.Session
+    member={Activate,Lock,Unlock,Terminate,Kill}
+    peer=(label=unconfined),
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "login-session-control",
+		summary:               loginSessionControlSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  loginSessionControlBaseDeclarationSlots,
+		connectedPlugAppArmor: loginSessionObserveConnectedPlugAppArmor + loginSessionControlConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
@@ -0,0 +1,87 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const loginSessionObserveSummary = `allows observing login and session information`
+
+const loginSessionObserveBaseDeclarationSlots = `
+  login-session-observe:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const loginSessionObserveConnectedPlugAppArmor = `
+# Description: Can query logind for the sessions and users on the system.
+# Can read all properties of /org/freedesktop/login1 D-Bus objects; see
+# https://www.freedesktop.org/wiki/Software/systemd/logind/
+
+#include <abstractions/dbus-strict>
+
+# Introspection of org.freedesktop.login1
+dbus (send)
+    bus=system
+    path=/org/freedesktop/login1{,/**}
+    interface=org.freedesktop.DBus.Introspectable
+    member=Introspect
+    peer=(label=unconfined),
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/login1
+    interface=org.freedesktop.login1.Manager
+    member={ListSessions,ListUsers,GetSession,GetSessionByPID,GetUser,GetUserByPID}
+    peer=(label=unconfined),
+
+# Read all properties from login1, its sessions and users
+dbus (send)
+    bus=system
+    path=/org/freedesktop/login1{,/**}
+    interface=org.freedesktop.DBus.Properties
+    member=Get{,All}
+    peer=(label=unconfined),
+
+# Receive login1 property changed events
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/login1{,/**}
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged
+    peer=(label=unconfined),
+
+# sd-login reads the session state directly
+/run/systemd/sessions/ r,
+/run/systemd/sessions/* r,
+/run/systemd/users/ r,
+/run/systemd/users/* r,
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "login-session-observe",
+		summary:               loginSessionObserveSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  loginSessionObserveBaseDeclarationSlots,
+		connectedPlugAppArmor: loginSessionObserveConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
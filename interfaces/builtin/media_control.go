@@ -0,0 +1,80 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const mediaControlSummary = `allows controlling the playback of MPRIS media players`
+
+const mediaControlBaseDeclarationSlots = `
+  media-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const mediaControlConnectedPlugAppArmor = `
+# Description: Can control the playback of any MPRIS media player in the
+# session, like pausing it or skipping to the next track.
+# See https://specifications.freedesktop.org/mpris-spec/latest/
+
+#include <abstractions/dbus-session-strict>
+
+# Find the media players, which reveals all names on the session bus
+dbus (send)
+    bus=session
+    path=/org/freedesktop/DBus
+    interface=org.freedesktop.DBus
+    member=ListNames
+    peer=(name=org.freedesktop.DBus, label=unconfined),
+
+# Control the playback
+dbus (send)
+    bus=session
+    path=/org/mpris/MediaPlayer2
+    interface=org.mpris.MediaPlayer2.Player
+    member={Play,Pause,PlayPause,Stop,Next,Previous}
+    peer=(name=org.mpris.MediaPlayer2.*),
+
+# Read the state of the players, like what is playing
+dbus (send)
+    bus=session
+    path=/org/mpris/MediaPlayer2
+    interface=org.freedesktop.DBus.Properties
+    member=Get{,All}
+    peer=(name=org.mpris.MediaPlayer2.*),
+
+# Receive the changes in the state of the players
+dbus (receive)
+    bus=session
+    path=/org/mpris/MediaPlayer2
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged,
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "media-control",
+		summary:               mediaControlSummary,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  mediaControlBaseDeclarationSlots,
+		connectedPlugAppArmor: mediaControlConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
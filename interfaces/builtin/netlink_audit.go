@@ -29,6 +29,14 @@ const netlinkAuditBaseDeclarationSlots = `
     deny-auto-connection: true
 `
 
+const netlinkAuditConnectedPlugAppArmor = `
+# Description: Can use netlink to read/write to kernel audit system.
+network netlink,
+
+# CAP_AUDIT_READ allows reading the audit log via a multicast netlink socket
+capability audit_read,
+`
+
 const netlinkAuditConnectedPlugSecComp = `
 # Description: Can use netlink to read/write to kernel audit system.
 bind
@@ -37,12 +45,13 @@ socket AF_NETLINK - NETLINK_AUDIT
 
 func init() {
 	registerIface(&commonInterface{
-		name:                 "netlink-audit",
-		summary:              netlinkAuditSummary,
-		implicitOnCore:       true,
-		implicitOnClassic:    true,
-		baseDeclarationSlots: netlinkAuditBaseDeclarationSlots,
-		connectedPlugSecComp: netlinkAuditConnectedPlugSecComp,
-		reservedForOS:        true,
+		name:                  "netlink-audit",
+		summary:               netlinkAuditSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  netlinkAuditBaseDeclarationSlots,
+		connectedPlugAppArmor: netlinkAuditConnectedPlugAppArmor,
+		connectedPlugSecComp:  netlinkAuditConnectedPlugSecComp,
+		reservedForOS:         true,
 	})
 }
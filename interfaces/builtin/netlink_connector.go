@@ -29,6 +29,17 @@ const netlinkConnectorBaseDeclarationSlots = `
     deny-auto-connection: true
 `
 
+const netlinkConnectorConnectedPlugAppArmor = `
+# Description: Can use netlink to communicate with kernel connector. The
+# process events connector reports fork, exec and exit of all processes on
+# the system, not just of this snap, so this should only be used with
+# trusted apps.
+network netlink dgram,
+
+# CAP_NET_ADMIN is required to subscribe to the process events
+capability net_admin,
+`
+
 const netlinkConnectorConnectedPlugSecComp = `
 # Description: Can use netlink to communicate with kernel connector. Because
 # NETLINK_CONNECTOR is not finely mediated and app-specific, use of this
@@ -40,12 +51,13 @@ socket AF_NETLINK - NETLINK_CONNECTOR
 
 func init() {
 	registerIface(&commonInterface{
-		name:                 "netlink-connector",
-		summary:              netlinkConnectorSummary,
-		implicitOnCore:       true,
-		implicitOnClassic:    true,
-		baseDeclarationSlots: netlinkConnectorBaseDeclarationSlots,
-		connectedPlugSecComp: netlinkConnectorConnectedPlugSecComp,
-		reservedForOS:        true,
+		name:                  "netlink-connector",
+		summary:               netlinkConnectorSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  netlinkConnectorBaseDeclarationSlots,
+		connectedPlugAppArmor: netlinkConnectorConnectedPlugAppArmor,
+		connectedPlugSecComp:  netlinkConnectorConnectedPlugSecComp,
+		reservedForOS:         true,
 	})
 }
@@ -0,0 +1,97 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const networkManagerObserveSummary = `allows observing NetworkManager devices and connection state`
+
+const networkManagerObserveBaseDeclarationSlots = `
+  network-manager-observe:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const networkManagerObserveConnectedPlugAppArmor = `
+# Description: Can read the state of NetworkManager, like the list of devices
+# and the state of connections, but cannot change any of its configuration.
+# See https://developer.gnome.org/NetworkManager/stable/spec.html
+
+#include <abstractions/dbus-strict>
+
+# Introspection of the NetworkManager objects
+dbus (send)
+    bus=system
+    path=/org/freedesktop/NetworkManager{,/**}
+    interface=org.freedesktop.DBus.Introspectable
+    member=Introspect
+    peer=(name=org.freedesktop.NetworkManager),
+
+# Read all properties of the NetworkManager objects
+dbus (send)
+    bus=system
+    path=/org/freedesktop/NetworkManager{,/**}
+    interface=org.freedesktop.DBus.Properties
+    member=Get{,All}
+    peer=(name=org.freedesktop.NetworkManager),
+
+# Receive property changed events
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/NetworkManager{,/**}
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged
+    peer=(label=unconfined),
+
+# Methods that only list devices and connections
+dbus (send)
+    bus=system
+    path=/org/freedesktop/NetworkManager
+    interface=org.freedesktop.NetworkManager
+    member={GetDevices,GetAllDevices,state}
+    peer=(name=org.freedesktop.NetworkManager),
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/NetworkManager/Settings
+    interface=org.freedesktop.NetworkManager.Settings
+    member=ListConnections
+    peer=(name=org.freedesktop.NetworkManager),
+
+# Receive state changes and devices coming and going
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/NetworkManager
+    interface=org.freedesktop.NetworkManager
+    member={StateChanged,DeviceAdded,DeviceRemoved}
+    peer=(label=unconfined),
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "network-manager-observe",
+		summary:               networkManagerObserveSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  networkManagerObserveBaseDeclarationSlots,
+		connectedPlugAppArmor: networkManagerObserveConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
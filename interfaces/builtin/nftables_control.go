@@ -0,0 +1,83 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const nftablesControlSummary = `allows control over network firewall with nftables`
+
+const nftablesControlBaseDeclarationSlots = `
+  nftables-control:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const nftablesControlConnectedPlugAppArmor = `
+# Description: Can configure the firewall with nftables. This is restricted
+# because it gives privileged access to networking and should only be used
+# with trusted apps.
+
+capability net_admin,
+
+/{,usr/}{,s}bin/nft ixr,
+
+# the default ruleset, as loaded by nftables.service
+/etc/nftables.conf rw,
+/etc/nftables/ r,
+/etc/nftables/** r,
+
+# nft resolves interface and service names
+/etc/iproute2/ r,
+/etc/iproute2/** r,
+/etc/services r,
+/etc/protocols r,
+
+# the netlink NETFILTER socket nft talks to the kernel over
+network netlink raw,
+
+@{PROC}/sys/net/netfilter/ r,
+@{PROC}/sys/net/netfilter/** r,
+@{PROC}/sys/kernel/modprobe r,
+
+# check whether nf_tables is loaded
+/sys/module/nf_tables/           r,
+/sys/module/nf_tables/initstate  r,
+`
+
+const nftablesControlConnectedPlugSecComp = `
+# Description: Can configure the firewall with nftables. This is restricted
+# because it gives privileged access to networking and should only be used
+# with trusted apps.
+
+bind
+socket AF_NETLINK - NETLINK_NETFILTER
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "nftables-control",
+		summary:               nftablesControlSummary,
+		implicitOnCore:        true,
+		baseDeclarationSlots:  nftablesControlBaseDeclarationSlots,
+		connectedPlugAppArmor: nftablesControlConnectedPlugAppArmor,
+		connectedPlugSecComp:  nftablesControlConnectedPlugSecComp,
+		reservedForOS:         true,
+	})
+}
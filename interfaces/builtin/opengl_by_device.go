@@ -0,0 +1,110 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+)
+
+const openglByDeviceSummary = `allows access to OpenGL stack on the GPU device named by the slot`
+
+const openglByDeviceBaseDeclarationSlots = `
+  opengl-by-device:
+    allow-installation:
+      slot-snap-type:
+        - core
+        - gadget
+    deny-auto-connection: true
+`
+
+// Pattern to match the DRM device nodes of a single GPU
+var openglByDeviceCardPattern = regexp.MustCompile(`^/dev/dri/(card|renderD)[0-9]{1,3}$`)
+
+// openglByDeviceCard returns the card attribute of the slot, checking
+// that it names a single DRM device node.
+func openglByDeviceCard(slot *interfaces.Slot) (string, error) {
+	card, ok := slot.Attrs["card"].(string)
+	if !ok || card == "" {
+		return "", fmt.Errorf("opengl-by-device slot must have a card attribute")
+	}
+	if filepath.Clean(card) != card || !openglByDeviceCardPattern.MatchString(card) {
+		return "", fmt.Errorf("opengl-by-device card attribute must be a DRM device node: %q", card)
+	}
+	return card, nil
+}
+
+func openglByDeviceConnectedPlugAppArmor(slot *interfaces.Slot) string {
+	card, err := openglByDeviceCard(slot)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(`
+# Description: Can access opengl on the GPU named by the slot only.
+
+  # specific gl libs
+  /var/lib/snapd/lib/gl/ r,
+  /var/lib/snapd/lib/gl/** rm,
+
+  /dev/dri/ r,
+  %s rw,
+
+  # /sys/devices
+  /sys/devices/pci[0-9]*/**/config r,
+  /sys/devices/pci[0-9]*/**/{,subsystem_}device r,
+  /sys/devices/pci[0-9]*/**/{,subsystem_}vendor r,
+  /sys/devices/**/drm{,_dp_aux_dev}/** r,
+
+  /run/udev/data/+drm:%s r,
+  /run/udev/data/+pci:[0-9]* r,
+  /run/udev/data/c226:[0-9]* r,  # 226 drm
+`, card, filepath.Base(card))
+}
+
+func openglByDeviceConnectedPlugUDev(slot *interfaces.Slot) string {
+	card, err := openglByDeviceCard(slot)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(`SUBSYSTEM=="drm", KERNEL=="%s", TAG+="###CONNECTED_SECURITY_TAGS###"`, strings.TrimPrefix(card, "/dev/dri/"))
+}
+
+func init() {
+	var iface *commonInterface
+	iface = &commonInterface{
+		name:                 "opengl-by-device",
+		summary:              openglByDeviceSummary,
+		baseDeclarationSlots: openglByDeviceBaseDeclarationSlots,
+		sanitizeSlot: func(slot *interfaces.Slot) error {
+			if err := sanitizeSlotReservedForOSOrGadget(iface, slot); err != nil {
+				return err
+			}
+			_, err := openglByDeviceCard(slot)
+			return err
+		},
+		connectedPlugAppArmorForSlot: openglByDeviceConnectedPlugAppArmor,
+		connectedPlugUDevForSlot:     openglByDeviceConnectedPlugUDev,
+	}
+	registerIface(iface)
+}
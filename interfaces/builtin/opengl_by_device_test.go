@@ -0,0 +1,176 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type OpenglByDeviceInterfaceSuite struct {
+	iface interfaces.Interface
+
+	// Core Snap
+	slot       *interfaces.Slot
+	renderSlot *interfaces.Slot
+
+	// Gadget Snap
+	gadgetSlot *interfaces.Slot
+
+	// App Snap
+	appSlot *interfaces.Slot
+
+	// Consuming Snap
+	plug *interfaces.Plug
+}
+
+var _ = Suite(&OpenglByDeviceInterfaceSuite{
+	iface: builtin.MustInterface("opengl-by-device"),
+})
+
+func (s *OpenglByDeviceInterfaceSuite) SetUpTest(c *C) {
+	coreSnapInfo := snaptest.MockInfo(c, `
+name: core
+type: os
+slots:
+    gpu:
+        interface: opengl-by-device
+        card: /dev/dri/card1
+    render:
+        interface: opengl-by-device
+        card: /dev/dri/renderD128
+`, nil)
+	s.slot = &interfaces.Slot{SlotInfo: coreSnapInfo.Slots["gpu"]}
+	s.renderSlot = &interfaces.Slot{SlotInfo: coreSnapInfo.Slots["render"]}
+
+	gadgetSnapInfo := snaptest.MockInfo(c, `
+name: some-device
+type: gadget
+slots:
+    display:
+        interface: opengl-by-device
+        card: /dev/dri/card0
+`, nil)
+	s.gadgetSlot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["display"]}
+
+	appSnapInfo := snaptest.MockInfo(c, `
+name: some-app
+slots:
+    gpu:
+        interface: opengl-by-device
+        card: /dev/dri/card1
+`, nil)
+	s.appSlot = &interfaces.Slot{SlotInfo: appSnapInfo.Slots["gpu"]}
+
+	consumingSnapInfo := snaptest.MockInfo(c, `
+name: client-snap
+apps:
+    app-accessing-gpu:
+        command: foo
+        plugs: [opengl-by-device]
+`, nil)
+	s.plug = &interfaces.Plug{PlugInfo: consumingSnapInfo.Plugs["opengl-by-device"]}
+}
+
+func (s *OpenglByDeviceInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "opengl-by-device")
+}
+
+func (s *OpenglByDeviceInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(s.slot.Sanitize(s.iface), IsNil)
+	c.Assert(s.renderSlot.Sanitize(s.iface), IsNil)
+	c.Assert(s.gadgetSlot.Sanitize(s.iface), IsNil)
+
+	c.Assert(s.appSlot.Sanitize(s.iface), ErrorMatches,
+		"opengl-by-device slots are reserved for the core and gadget snaps")
+}
+
+func (s *OpenglByDeviceInterfaceSuite) TestSanitizeSlotBadAttrs(c *C) {
+	for _, t := range []struct {
+		attrs map[string]interface{}
+		err   string
+	}{
+		{nil, "opengl-by-device slot must have a card attribute"},
+		{map[string]interface{}{"card": ""}, "opengl-by-device slot must have a card attribute"},
+		{map[string]interface{}{"card": 1}, "opengl-by-device slot must have a card attribute"},
+		{map[string]interface{}{"card": "/dev/dri/../sda"}, `opengl-by-device card attribute must be a DRM device node: "/dev/dri/../sda"`},
+		{map[string]interface{}{"card": "/dev/dri/card*"}, `opengl-by-device card attribute must be a DRM device node: .*`},
+		{map[string]interface{}{"card": "/dev/dri/controlD64"}, `opengl-by-device card attribute must be a DRM device node: .*`},
+		{map[string]interface{}{"card": "/dev/dri/"}, `opengl-by-device card attribute must be a DRM device node: .*`},
+		{map[string]interface{}{"card": "/dev/nvidia0"}, `opengl-by-device card attribute must be a DRM device node: .*`},
+		{map[string]interface{}{"card": "card0"}, `opengl-by-device card attribute must be a DRM device node: .*`},
+	} {
+		slot := &interfaces.Slot{SlotInfo: &snap.SlotInfo{
+			Snap:      s.slot.Snap,
+			Name:      "gpu",
+			Interface: "opengl-by-device",
+			Attrs:     t.attrs,
+		}}
+		c.Check(slot.Sanitize(s.iface), ErrorMatches, t.err, Commentf("%v", t.attrs))
+	}
+}
+
+func (s *OpenglByDeviceInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(s.plug.Sanitize(s.iface), IsNil)
+}
+
+func (s *OpenglByDeviceInterfaceSuite) TestAppArmorSpec(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.client-snap.app-accessing-gpu"})
+	snippet := spec.SnippetForTag("snap.client-snap.app-accessing-gpu")
+	c.Check(snippet, testutil.Contains, "\n  /dev/dri/card1 rw,\n")
+	c.Check(snippet, testutil.Contains, "\n  /run/udev/data/+drm:card1 r,\n")
+	// no other GPU
+	c.Check(snippet, Not(testutil.Contains), "/dev/dri/card0")
+	c.Check(snippet, Not(testutil.Contains), "/dev/nvidia")
+
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.gadgetSlot, nil), IsNil)
+	snippet = spec.SnippetForTag("snap.client-snap.app-accessing-gpu")
+	c.Check(snippet, testutil.Contains, "\n  /dev/dri/card0 rw,\n")
+	c.Check(snippet, Not(testutil.Contains), "/dev/dri/card1")
+}
+
+func (s *OpenglByDeviceInterfaceSuite) TestUDevSpec(c *C) {
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.renderSlot, nil), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 1)
+	c.Check(spec.Snippets()[0], Equals, `SUBSYSTEM=="drm", KERNEL=="renderD128", TAG+="snap_client-snap_app-accessing-gpu"`)
+}
+
+func (s *OpenglByDeviceInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, false)
+	c.Assert(si.Summary, Equals, "allows access to OpenGL stack on the GPU device named by the slot")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "opengl-by-device")
+}
+
+func (s *OpenglByDeviceInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
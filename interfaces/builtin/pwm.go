@@ -0,0 +1,97 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/interfaces"
+)
+
+const pwmSummary = `allows access to a specific PWM channel`
+
+const pwmBaseDeclarationSlots = `
+  pwm:
+    allow-installation:
+      slot-snap-type:
+        - core
+        - gadget
+    deny-auto-connection: true
+`
+
+// pwmAttrs returns the chip and channel attributes of the slot, checking
+// that they are valid.
+func pwmAttrs(slot *interfaces.Slot) (chip, channel int64, err error) {
+	for _, attr := range []struct {
+		name  string
+		value *int64
+	}{{"chip", &chip}, {"channel", &channel}} {
+		v, ok := slot.Attrs[attr.name]
+		if !ok {
+			return 0, 0, fmt.Errorf("pwm slot must have a %s attribute", attr.name)
+		}
+		n, ok := v.(int64)
+		if !ok || n < 0 {
+			return 0, 0, fmt.Errorf("pwm slot %s attribute must be a non-negative int", attr.name)
+		}
+		*attr.value = n
+	}
+	return chip, channel, nil
+}
+
+func pwmConnectedPlugAppArmor(slot *interfaces.Slot) string {
+	chip, channel, err := pwmAttrs(slot)
+	if err != nil {
+		return ""
+	}
+	// The entries in /sys/class/pwm are symlinks to the chips in the
+	// sysfs device tree, which apparmor requires to be dereferenced.
+	// Chip numbers are unique so the chip can be matched anywhere in
+	// there without looking at the host sysfs.
+	return fmt.Sprintf(`
+# Description: Allow exporting and controlling the PWM channel named by the
+# slot.
+
+/sys/class/pwm/ r,
+/sys/devices/**/pwm/pwmchip%[1]d/ r,
+/sys/devices/**/pwm/pwmchip%[1]d/npwm r,
+/sys/devices/**/pwm/pwmchip%[1]d/{,un}export w,
+/sys/devices/**/pwm/pwmchip%[1]d/pwm%[2]d/ r,
+/sys/devices/**/pwm/pwmchip%[1]d/pwm%[2]d/{period,duty_cycle,enable,polarity} rw,
+`, chip, channel)
+}
+
+func init() {
+	var iface *commonInterface
+	iface = &commonInterface{
+		name:                 "pwm",
+		summary:              pwmSummary,
+		baseDeclarationSlots: pwmBaseDeclarationSlots,
+		sanitizeSlot: func(slot *interfaces.Slot) error {
+			if err := sanitizeSlotReservedForOSOrGadget(iface, slot); err != nil {
+				return err
+			}
+			_, _, err := pwmAttrs(slot)
+			return err
+		},
+		connectedPlugAppArmorForSlot: pwmConnectedPlugAppArmor,
+	}
+	registerIface(iface)
+}
@@ -0,0 +1,168 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type PwmInterfaceSuite struct {
+	iface interfaces.Interface
+
+	// Core Snap
+	slot *interfaces.Slot
+
+	// Gadget Snap
+	gadgetSlot *interfaces.Slot
+
+	// App Snap
+	appSlot *interfaces.Slot
+
+	// Consuming Snap
+	plug *interfaces.Plug
+}
+
+var _ = Suite(&PwmInterfaceSuite{
+	iface: builtin.MustInterface("pwm"),
+})
+
+func (s *PwmInterfaceSuite) SetUpTest(c *C) {
+	coreSnapInfo := snaptest.MockInfo(c, `
+name: core
+type: os
+slots:
+    pwm0:
+        interface: pwm
+        chip: 0
+        channel: 1
+`, nil)
+	s.slot = &interfaces.Slot{SlotInfo: coreSnapInfo.Slots["pwm0"]}
+
+	gadgetSnapInfo := snaptest.MockInfo(c, `
+name: some-device
+type: gadget
+slots:
+    fan:
+        interface: pwm
+        chip: 1
+        channel: 0
+`, nil)
+	s.gadgetSlot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["fan"]}
+
+	appSnapInfo := snaptest.MockInfo(c, `
+name: some-app
+slots:
+    pwm0:
+        interface: pwm
+        chip: 0
+        channel: 1
+`, nil)
+	s.appSlot = &interfaces.Slot{SlotInfo: appSnapInfo.Slots["pwm0"]}
+
+	consumingSnapInfo := snaptest.MockInfo(c, `
+name: client-snap
+apps:
+    app-accessing-pwm:
+        command: foo
+        plugs: [pwm]
+`, nil)
+	s.plug = &interfaces.Plug{PlugInfo: consumingSnapInfo.Plugs["pwm"]}
+}
+
+func (s *PwmInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "pwm")
+}
+
+func (s *PwmInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(s.slot.Sanitize(s.iface), IsNil)
+	c.Assert(s.gadgetSlot.Sanitize(s.iface), IsNil)
+	c.Assert(s.appSlot.Sanitize(s.iface), ErrorMatches,
+		"pwm slots are reserved for the core and gadget snaps")
+}
+
+func (s *PwmInterfaceSuite) TestSanitizeSlotBadAttrs(c *C) {
+	for _, t := range []struct {
+		attrs map[string]interface{}
+		err   string
+	}{
+		{nil, "pwm slot must have a chip attribute"},
+		{map[string]interface{}{"chip": int64(0)}, "pwm slot must have a channel attribute"},
+		{map[string]interface{}{"channel": int64(0)}, "pwm slot must have a chip attribute"},
+		{map[string]interface{}{"chip": "0", "channel": int64(1)}, "pwm slot chip attribute must be a non-negative int"},
+		{map[string]interface{}{"chip": int64(-1), "channel": int64(1)}, "pwm slot chip attribute must be a non-negative int"},
+		{map[string]interface{}{"chip": int64(0), "channel": "*"}, "pwm slot channel attribute must be a non-negative int"},
+		{map[string]interface{}{"chip": int64(0), "channel": int64(-2)}, "pwm slot channel attribute must be a non-negative int"},
+	} {
+		slot := &interfaces.Slot{SlotInfo: &snap.SlotInfo{
+			Snap:      s.slot.Snap,
+			Name:      "pwm0",
+			Interface: "pwm",
+			Attrs:     t.attrs,
+		}}
+		c.Check(slot.Sanitize(s.iface), ErrorMatches, t.err, Commentf("%v", t.attrs))
+	}
+}
+
+func (s *PwmInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(s.plug.Sanitize(s.iface), IsNil)
+}
+
+func (s *PwmInterfaceSuite) TestAppArmorSpec(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.client-snap.app-accessing-pwm"})
+	snippet := spec.SnippetForTag("snap.client-snap.app-accessing-pwm")
+	c.Check(snippet, testutil.Contains, "/sys/devices/**/pwm/pwmchip0/{,un}export w,\n")
+	c.Check(snippet, testutil.Contains, "/sys/devices/**/pwm/pwmchip0/pwm1/{period,duty_cycle,enable,polarity} rw,\n")
+	// no other chips nor channels
+	c.Check(snippet, Not(testutil.Contains), "pwmchip*")
+	c.Check(snippet, Not(testutil.Contains), "pwm[0-9]")
+
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.gadgetSlot, nil), IsNil)
+	snippet = spec.SnippetForTag("snap.client-snap.app-accessing-pwm")
+	c.Check(snippet, testutil.Contains, "/sys/devices/**/pwm/pwmchip1/pwm0/{period,duty_cycle,enable,polarity} rw,\n")
+}
+
+func (s *PwmInterfaceSuite) TestSecCompSpec(c *C) {
+	spec := &seccomp.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), HasLen, 0)
+}
+
+func (s *PwmInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, false)
+	c.Assert(si.Summary, Equals, "allows access to a specific PWM channel")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "pwm")
+}
+
+func (s *PwmInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
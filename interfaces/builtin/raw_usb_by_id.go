@@ -0,0 +1,98 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/interfaces"
+)
+
+const rawusbByIDSummary = `allows raw access to USB devices with a given vendor and product ID`
+
+const rawusbByIDBaseDeclarationSlots = `
+  raw-usb-by-id:
+    allow-installation:
+      slot-snap-type:
+        - core
+        - gadget
+    deny-auto-connection: true
+`
+
+const rawusbByIDConnectedPlugAppArmor = `
+# Description: Allow raw access to the USB devices matching the vendor and
+# product ID of the slot. USB device nodes are named after their bus and
+# device numbers, which are only known once the device is plugged in, and
+# apparmor mediates the node itself rather than any udev symlink to it, so
+# the path cannot carry the vendor and product ID. Access is narrowed down
+# to the matching devices by the udev tag and the device cgroup instead.
+/dev/bus/usb/[0-9][0-9][0-9]/[0-9][0-9][0-9] rw,
+
+# Allow detection of usb devices. Leaks plugged in USB device info
+/sys/bus/usb/devices/ r,
+/sys/devices/pci**/usb[0-9]** r,
+/sys/devices/platform/soc/*.usb/usb[0-9]** r,
+
+/run/udev/data/+usb:* r,
+`
+
+// rawusbByIDAttrs returns the usb-vendor and usb-product attributes of
+// the slot, checking that they are valid.
+func rawusbByIDAttrs(slot *interfaces.Slot) (usbVendor, usbProduct int64, err error) {
+	usbVendor, ok := slot.Attrs["usb-vendor"].(int64)
+	if !ok {
+		return 0, 0, fmt.Errorf("raw-usb-by-id slot must have an usb-vendor attribute")
+	}
+	if usbVendor < 0x1 || usbVendor > 0xFFFF {
+		return 0, 0, fmt.Errorf("raw-usb-by-id usb-vendor attribute not valid: %d", usbVendor)
+	}
+	usbProduct, ok = slot.Attrs["usb-product"].(int64)
+	if !ok {
+		return 0, 0, fmt.Errorf("raw-usb-by-id slot must have an usb-product attribute")
+	}
+	if usbProduct < 0x0 || usbProduct > 0xFFFF {
+		return 0, 0, fmt.Errorf("raw-usb-by-id usb-product attribute not valid: %d", usbProduct)
+	}
+	return usbVendor, usbProduct, nil
+}
+
+func init() {
+	var iface *commonInterface
+	iface = &commonInterface{
+		name:                  "raw-usb-by-id",
+		summary:               rawusbByIDSummary,
+		baseDeclarationSlots:  rawusbByIDBaseDeclarationSlots,
+		connectedPlugAppArmor: rawusbByIDConnectedPlugAppArmor,
+		sanitizeSlot: func(slot *interfaces.Slot) error {
+			if err := sanitizeSlotReservedForOSOrGadget(iface, slot); err != nil {
+				return err
+			}
+			_, _, err := rawusbByIDAttrs(slot)
+			return err
+		},
+		connectedPlugUDevForSlot: func(slot *interfaces.Slot) string {
+			usbVendor, usbProduct, err := rawusbByIDAttrs(slot)
+			if err != nil {
+				return ""
+			}
+			return udevUsbDeviceSnippet("usb", usbVendor, usbProduct, "TAG", "###CONNECTED_SECURITY_TAGS###")
+		},
+	}
+	registerIface(iface)
+}
@@ -0,0 +1,179 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type RawUsbByIDInterfaceSuite struct {
+	iface interfaces.Interface
+
+	// Gadget Snap
+	testSlot           *interfaces.Slot
+	missingVendorSlot  *interfaces.Slot
+	missingProductSlot *interfaces.Slot
+	badVendorSlot      *interfaces.Slot
+	badProductSlot     *interfaces.Slot
+
+	// App Snap
+	appSlot *interfaces.Slot
+
+	// Consuming Snap
+	plug *interfaces.Plug
+}
+
+var _ = Suite(&RawUsbByIDInterfaceSuite{
+	iface: builtin.MustInterface("raw-usb-by-id"),
+})
+
+func (s *RawUsbByIDInterfaceSuite) SetUpTest(c *C) {
+	gadgetSnapInfo := snaptest.MockInfo(c, `
+name: some-device
+type: gadget
+slots:
+    test-slot:
+        interface: raw-usb-by-id
+        usb-vendor: 0x0001
+        usb-product: 0x0001
+    missing-vendor:
+        interface: raw-usb-by-id
+        usb-product: 0x0001
+    missing-product:
+        interface: raw-usb-by-id
+        usb-vendor: 0x0001
+    bad-vendor:
+        interface: raw-usb-by-id
+        usb-vendor: 0x10000
+        usb-product: 0x0001
+    bad-product:
+        interface: raw-usb-by-id
+        usb-vendor: 0x0001
+        usb-product: -1
+`, nil)
+	s.testSlot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["test-slot"]}
+	s.missingVendorSlot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["missing-vendor"]}
+	s.missingProductSlot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["missing-product"]}
+	s.badVendorSlot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["bad-vendor"]}
+	s.badProductSlot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["bad-product"]}
+
+	appSnapInfo := snaptest.MockInfo(c, `
+name: some-app
+slots:
+    test-slot:
+        interface: raw-usb-by-id
+        usb-vendor: 0x0001
+        usb-product: 0x0001
+`, nil)
+	s.appSlot = &interfaces.Slot{SlotInfo: appSnapInfo.Slots["test-slot"]}
+
+	consumingSnapInfo := snaptest.MockInfo(c, `
+name: client-snap
+apps:
+    app-accessing-device:
+        command: foo
+        plugs: [raw-usb-by-id]
+`, nil)
+	s.plug = &interfaces.Plug{PlugInfo: consumingSnapInfo.Plugs["raw-usb-by-id"]}
+}
+
+func (s *RawUsbByIDInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "raw-usb-by-id")
+}
+
+func (s *RawUsbByIDInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(s.testSlot.Sanitize(s.iface), IsNil)
+
+	c.Assert(s.appSlot.Sanitize(s.iface), ErrorMatches,
+		"raw-usb-by-id slots are reserved for the core and gadget snaps")
+	c.Assert(s.missingVendorSlot.Sanitize(s.iface), ErrorMatches,
+		"raw-usb-by-id slot must have an usb-vendor attribute")
+	c.Assert(s.missingProductSlot.Sanitize(s.iface), ErrorMatches,
+		"raw-usb-by-id slot must have an usb-product attribute")
+	c.Assert(s.badVendorSlot.Sanitize(s.iface), ErrorMatches,
+		"raw-usb-by-id usb-vendor attribute not valid: 65536")
+	c.Assert(s.badProductSlot.Sanitize(s.iface), ErrorMatches,
+		"raw-usb-by-id usb-product attribute not valid: -1")
+}
+
+func (s *RawUsbByIDInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(s.plug.Sanitize(s.iface), IsNil)
+}
+
+func (s *RawUsbByIDInterfaceSuite) TestAppArmorSpec(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.testSlot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.client-snap.app-accessing-device"})
+	c.Check(spec.SnippetForTag("snap.client-snap.app-accessing-device"), testutil.Contains,
+		"/dev/bus/usb/[0-9][0-9][0-9]/[0-9][0-9][0-9] rw,")
+}
+
+func (s *RawUsbByIDInterfaceSuite) TestUDevSpec(c *C) {
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.testSlot, nil), IsNil)
+	c.Assert(spec.Snippets(), DeepEquals, []string{`IMPORT{builtin}="usb_id"
+SUBSYSTEM=="usb", SUBSYSTEMS=="usb", ATTRS{idVendor}=="0001", ATTRS{idProduct}=="0001", TAG+="snap_client-snap_app-accessing-device"`})
+}
+
+func (s *RawUsbByIDInterfaceSuite) TestUDevSpecOnlyTagsSlotDevice(c *C) {
+	otherSnapInfo := snaptest.MockInfo(c, `
+name: other-device
+type: gadget
+slots:
+    other-slot:
+        interface: raw-usb-by-id
+        usb-vendor: 0x0002
+        usb-product: 0x0003
+`, nil)
+	otherSlot := &interfaces.Slot{SlotInfo: otherSnapInfo.Slots["other-slot"]}
+
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.testSlot, nil), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 1)
+	c.Check(spec.Snippets()[0], Not(testutil.Contains), `ATTRS{idVendor}=="0002"`)
+	c.Check(spec.Snippets()[0], Not(testutil.Contains), `ATTRS{idProduct}=="0003"`)
+
+	spec = &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, otherSlot, nil), IsNil)
+	c.Assert(spec.Snippets(), DeepEquals, []string{`IMPORT{builtin}="usb_id"
+SUBSYSTEM=="usb", SUBSYSTEMS=="usb", ATTRS{idVendor}=="0002", ATTRS{idProduct}=="0003", TAG+="snap_client-snap_app-accessing-device"`})
+}
+
+func (s *RawUsbByIDInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, false)
+	c.Assert(si.Summary, Equals, "allows raw access to USB devices with a given vendor and product ID")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "raw-usb-by-id")
+}
+
+func (s *RawUsbByIDInterfaceSuite) TestAutoConnect(c *C) {
+	c.Check(s.iface.AutoConnect(s.plug, s.testSlot), Equals, true)
+}
+
+func (s *RawUsbByIDInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
@@ -0,0 +1,97 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+)
+
+const rawVolumeSummary = `allows read/write access to the block device partition named by the slot`
+
+const rawVolumeBaseDeclarationSlots = `
+  raw-volume:
+    allow-installation:
+      slot-snap-type:
+        - core
+        - gadget
+    deny-auto-connection: true
+`
+
+// rawVolumePath returns the path attribute of the slot, checking that
+// it names a single block device partition.
+func rawVolumePath(slot *interfaces.Slot) (string, error) {
+	path, ok := slot.Attrs["path"].(string)
+	if !ok || path == "" {
+		return "", fmt.Errorf("raw-volume slot must have a path attribute")
+	}
+	if filepath.Clean(path) != path {
+		return "", fmt.Errorf("raw-volume path attribute must be a clean path: %q", path)
+	}
+	if !blockDevicesPartitionPattern.MatchString(path) {
+		return "", fmt.Errorf("raw-volume path attribute must name a block device partition: %q", path)
+	}
+	return path, nil
+}
+
+func rawVolumeConnectedPlugAppArmor(slot *interfaces.Slot) string {
+	path, err := rawVolumePath(slot)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(`
+# Description: Allow read/write access to the block device partition named
+# by the slot, for formatting or imaging it. This gives full control over
+# the data on the partition and should only be used with trusted apps.
+
+%s rw,
+/run/udev/data/b[0-9]*:[0-9]* r,
+`, path)
+}
+
+func rawVolumeConnectedPlugUDev(slot *interfaces.Slot) string {
+	path, err := rawVolumePath(slot)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(`SUBSYSTEM=="block", KERNEL=="%s", TAG+="###CONNECTED_SECURITY_TAGS###"`, strings.TrimPrefix(path, "/dev/"))
+}
+
+func init() {
+	var iface *commonInterface
+	iface = &commonInterface{
+		name:                 "raw-volume",
+		summary:              rawVolumeSummary,
+		baseDeclarationSlots: rawVolumeBaseDeclarationSlots,
+		sanitizeSlot: func(slot *interfaces.Slot) error {
+			if err := sanitizeSlotReservedForOSOrGadget(iface, slot); err != nil {
+				return err
+			}
+			_, err := rawVolumePath(slot)
+			return err
+		},
+		connectedPlugAppArmorForSlot: rawVolumeConnectedPlugAppArmor,
+		connectedPlugUDevForSlot:     rawVolumeConnectedPlugUDev,
+	}
+	registerIface(iface)
+}
@@ -0,0 +1,173 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type RawVolumeInterfaceSuite struct {
+	iface interfaces.Interface
+
+	// Core Snap
+	slot     *interfaces.Slot
+	nvmeSlot *interfaces.Slot
+
+	// Gadget Snap
+	gadgetSlot *interfaces.Slot
+
+	// App Snap
+	appSlot *interfaces.Slot
+
+	// Consuming Snap
+	plug *interfaces.Plug
+}
+
+var _ = Suite(&RawVolumeInterfaceSuite{
+	iface: builtin.MustInterface("raw-volume"),
+})
+
+func (s *RawVolumeInterfaceSuite) SetUpTest(c *C) {
+	coreSnapInfo := snaptest.MockInfo(c, `
+name: core
+type: os
+slots:
+    data:
+        interface: raw-volume
+        path: /dev/sdb2
+    nvme-data:
+        interface: raw-volume
+        path: /dev/nvme0n1p3
+`, nil)
+	s.slot = &interfaces.Slot{SlotInfo: coreSnapInfo.Slots["data"]}
+	s.nvmeSlot = &interfaces.Slot{SlotInfo: coreSnapInfo.Slots["nvme-data"]}
+
+	gadgetSnapInfo := snaptest.MockInfo(c, `
+name: some-device
+type: gadget
+slots:
+    writable-data:
+        interface: raw-volume
+        path: /dev/mmcblk0p4
+`, nil)
+	s.gadgetSlot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["writable-data"]}
+
+	appSnapInfo := snaptest.MockInfo(c, `
+name: some-app
+slots:
+    data:
+        interface: raw-volume
+        path: /dev/sdb2
+`, nil)
+	s.appSlot = &interfaces.Slot{SlotInfo: appSnapInfo.Slots["data"]}
+
+	consumingSnapInfo := snaptest.MockInfo(c, `
+name: client-snap
+apps:
+    app-accessing-volume:
+        command: foo
+        plugs: [raw-volume]
+`, nil)
+	s.plug = &interfaces.Plug{PlugInfo: consumingSnapInfo.Plugs["raw-volume"]}
+}
+
+func (s *RawVolumeInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "raw-volume")
+}
+
+func (s *RawVolumeInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(s.slot.Sanitize(s.iface), IsNil)
+	c.Assert(s.nvmeSlot.Sanitize(s.iface), IsNil)
+	c.Assert(s.gadgetSlot.Sanitize(s.iface), IsNil)
+
+	c.Assert(s.appSlot.Sanitize(s.iface), ErrorMatches,
+		"raw-volume slots are reserved for the core and gadget snaps")
+}
+
+func (s *RawVolumeInterfaceSuite) TestSanitizeSlotBadAttrs(c *C) {
+	for _, t := range []struct {
+		attrs map[string]interface{}
+		err   string
+	}{
+		{nil, "raw-volume slot must have a path attribute"},
+		{map[string]interface{}{"path": ""}, "raw-volume slot must have a path attribute"},
+		{map[string]interface{}{"path": 1}, "raw-volume slot must have a path attribute"},
+		{map[string]interface{}{"path": "/dev/../dev/sdb2"}, `raw-volume path attribute must be a clean path: "/dev/../dev/sdb2"`},
+		{map[string]interface{}{"path": "/dev/sdb"}, `raw-volume path attribute must name a block device partition: "/dev/sdb"`},
+		{map[string]interface{}{"path": "/dev/nvme0n1"}, `raw-volume path attribute must name a block device partition: .*`},
+		{map[string]interface{}{"path": "/dev/sdb*"}, `raw-volume path attribute must name a block device partition: .*`},
+		{map[string]interface{}{"path": "/dev/tty1"}, `raw-volume path attribute must name a block device partition: .*`},
+		{map[string]interface{}{"path": "/tmp/sdb2"}, `raw-volume path attribute must name a block device partition: .*`},
+		{map[string]interface{}{"path": "sdb2"}, `raw-volume path attribute must name a block device partition: .*`},
+	} {
+		slot := &interfaces.Slot{SlotInfo: &snap.SlotInfo{
+			Snap:      s.slot.Snap,
+			Name:      "data",
+			Interface: "raw-volume",
+			Attrs:     t.attrs,
+		}}
+		c.Check(slot.Sanitize(s.iface), ErrorMatches, t.err, Commentf("%v", t.attrs))
+	}
+}
+
+func (s *RawVolumeInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(s.plug.Sanitize(s.iface), IsNil)
+}
+
+func (s *RawVolumeInterfaceSuite) TestAppArmorSpec(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.client-snap.app-accessing-volume"})
+	snippet := spec.SnippetForTag("snap.client-snap.app-accessing-volume")
+	c.Check(snippet, testutil.Contains, "\n/dev/sdb2 rw,\n")
+	c.Check(snippet, Not(testutil.Contains), "/dev/sdb rw,")
+
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.gadgetSlot, nil), IsNil)
+	snippet = spec.SnippetForTag("snap.client-snap.app-accessing-volume")
+	c.Check(snippet, testutil.Contains, "\n/dev/mmcblk0p4 rw,\n")
+}
+
+func (s *RawVolumeInterfaceSuite) TestUDevSpec(c *C) {
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.nvmeSlot, nil), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 1)
+	c.Check(spec.Snippets()[0], Equals, `SUBSYSTEM=="block", KERNEL=="nvme0n1p3", TAG+="snap_client-snap_app-accessing-volume"`)
+}
+
+func (s *RawVolumeInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, false)
+	c.Assert(si.Summary, Equals, "allows read/write access to the block device partition named by the slot")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "raw-volume")
+}
+
+func (s *RawVolumeInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
@@ -45,7 +45,15 @@ dbus (send)
     bus=system
     path=/org/freedesktop/login1
     interface=org.freedesktop.login1.Manager
-    member={PowerOff,Reboot,Suspend,Hibernate,HybridSleep,CanPowerOff,CanReboot,CanSuspend,CanHibernate,CanHybridSleep,ScheduleShutdown,CancelScheduledShutdown}
+    member={PowerOff,Reboot,Halt,Suspend,Hibernate,HybridSleep,CanPowerOff,CanReboot,CanHalt,CanSuspend,CanHibernate,CanHybridSleep,ScheduleShutdown,CancelScheduledShutdown}
+    peer=(label=unconfined),
+
+# Read the ScheduledShutdown property of logind
+dbus (send)
+    bus=system
+    path=/org/freedesktop/login1
+    interface=org.freedesktop.DBus.Properties
+    member=Get
     peer=(label=unconfined),
 
 # Allow clients to introspect
@@ -62,6 +70,11 @@ dbus (send)
     interface=org.freedesktop.DBus.Introspectable
     member=Introspect
     peer=(label=unconfined),
+
+# shutdown is systemctl, which schedules delayed shutdowns via logind
+/{,usr/}sbin/shutdown ixr,
+/{,usr/}bin/systemctl ixr,
+/run/systemd/shutdown/scheduled r,
 `
 
 func init() {
@@ -82,6 +82,10 @@ func (s *ShutdownInterfaceSuite) TestConnectedPlugSnippet(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(apparmorSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
 	c.Assert(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, `org.freedesktop.systemd1`)
+	c.Assert(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, `member={PowerOff,Reboot,Halt,`)
+	c.Assert(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, `CanPowerOff,CanReboot,CanHalt,`)
+	c.Assert(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, `ScheduleShutdown,CancelScheduledShutdown}`)
+	c.Assert(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, "/{,usr/}sbin/shutdown ixr,\n")
 }
 
 func (s *ShutdownInterfaceSuite) TestInterfaces(c *C) {
@@ -0,0 +1,100 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/interfaces"
+)
+
+const sshKeysSummary = `allows reading the SSH host keys`
+
+// Reading the private host keys needs to be allowed case by case with
+// a snap declaration.
+const sshKeysBaseDeclarationPlugs = `
+  ssh-keys:
+    deny-auto-connection: true
+    deny-connection:
+      plug-attributes:
+        private-keys: true
+`
+
+const sshKeysBaseDeclarationSlots = `
+  ssh-keys:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const sshKeysConnectedPlugAppArmor = `
+# Description: Can read the public SSH host keys, to verify the identity of
+# the host. Access is read-only.
+/etc/ssh/ r,
+/etc/ssh/ssh_host_*_key.pub r,
+`
+
+const sshKeysPrivateConnectedPlugAppArmor = `
+# Description: Can read the private SSH host keys as well, as asked for by
+# the private-keys attribute of the plug. This allows impersonating the host
+# and should only be used with trusted apps.
+/etc/ssh/ssh_host_*_key r,
+`
+
+// sshKeysPrivateKeys returns whether the plug asks for access to the
+// private host keys too.
+func sshKeysPrivateKeys(plug *interfaces.Plug) (bool, error) {
+	v, ok := plug.Attrs["private-keys"]
+	if !ok {
+		return false, nil
+	}
+	privateKeys, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("ssh-keys plug private-keys attribute must be a boolean")
+	}
+	return privateKeys, nil
+}
+
+func init() {
+	registerIface(&commonInterface{
+		name:                 "ssh-keys",
+		summary:              sshKeysSummary,
+		implicitOnCore:       true,
+		implicitOnClassic:    true,
+		baseDeclarationPlugs: sshKeysBaseDeclarationPlugs,
+		baseDeclarationSlots: sshKeysBaseDeclarationSlots,
+		reservedForOS:        true,
+		sanitizePlug: func(plug *interfaces.Plug) error {
+			_, err := sshKeysPrivateKeys(plug)
+			return err
+		},
+		connectedPlugAppArmorForPlug: func(plug *interfaces.Plug) string {
+			privateKeys, err := sshKeysPrivateKeys(plug)
+			if err != nil {
+				return ""
+			}
+			if privateKeys {
+				return sshKeysConnectedPlugAppArmor + sshKeysPrivateConnectedPlugAppArmor
+			}
+			return sshKeysConnectedPlugAppArmor
+		},
+	})
+}
@@ -0,0 +1,126 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type SshKeysInterfaceSuite struct {
+	iface       interfaces.Interface
+	slot        *interfaces.Slot
+	plug        *interfaces.Plug
+	privatePlug *interfaces.Plug
+}
+
+var _ = Suite(&SshKeysInterfaceSuite{
+	iface: builtin.MustInterface("ssh-keys"),
+})
+
+func (s *SshKeysInterfaceSuite) SetUpTest(c *C) {
+	const mockPlugSnapInfo = `name: other
+version: 1.0
+plugs:
+ host-keys:
+  interface: ssh-keys
+  private-keys: true
+apps:
+ app:
+  command: foo
+  plugs: [ssh-keys, host-keys]
+`
+	s.slot = &interfaces.Slot{
+		SlotInfo: &snap.SlotInfo{
+			Snap:      &snap.Info{SuggestedName: "core", Type: snap.TypeOS},
+			Name:      "ssh-keys",
+			Interface: "ssh-keys",
+		},
+	}
+	plugSnap := snaptest.MockInfo(c, mockPlugSnapInfo, nil)
+	s.plug = &interfaces.Plug{PlugInfo: plugSnap.Plugs["ssh-keys"]}
+	s.privatePlug = &interfaces.Plug{PlugInfo: plugSnap.Plugs["host-keys"]}
+}
+
+func (s *SshKeysInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "ssh-keys")
+}
+
+func (s *SshKeysInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(s.slot.Sanitize(s.iface), IsNil)
+	slot := &interfaces.Slot{SlotInfo: &snap.SlotInfo{
+		Snap:      &snap.Info{SuggestedName: "some-snap"},
+		Name:      "ssh-keys",
+		Interface: "ssh-keys",
+	}}
+	c.Assert(slot.Sanitize(s.iface), ErrorMatches, "ssh-keys slots are reserved for the core snap")
+}
+
+func (s *SshKeysInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(s.plug.Sanitize(s.iface), IsNil)
+	c.Assert(s.privatePlug.Sanitize(s.iface), IsNil)
+
+	plug := &interfaces.Plug{PlugInfo: &snap.PlugInfo{
+		Snap:      s.plug.Snap,
+		Name:      "ssh-keys",
+		Interface: "ssh-keys",
+		Attrs:     map[string]interface{}{"private-keys": "yes"},
+	}}
+	c.Assert(plug.Sanitize(s.iface), ErrorMatches, "ssh-keys plug private-keys attribute must be a boolean")
+}
+
+func (s *SshKeysInterfaceSuite) TestUsedSecuritySystems(c *C) {
+	// by default only the public keys can be read
+	apparmorSpec := &apparmor.Specification{}
+	err := apparmorSpec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil)
+	c.Assert(err, IsNil)
+	c.Assert(apparmorSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
+	snippet := apparmorSpec.SnippetForTag("snap.other.app")
+	c.Check(snippet, testutil.Contains, "/etc/ssh/ssh_host_*_key.pub r,\n")
+	c.Check(snippet, Not(testutil.Contains), "/etc/ssh/ssh_host_*_key r,\n")
+	// access is read-only
+	c.Check(snippet, Not(testutil.Contains), "w,")
+
+	// the private keys only when asked for
+	apparmorSpec = &apparmor.Specification{}
+	err = apparmorSpec.AddConnectedPlug(s.iface, s.privatePlug, nil, s.slot, nil)
+	c.Assert(err, IsNil)
+	snippet = apparmorSpec.SnippetForTag("snap.other.app")
+	c.Check(snippet, testutil.Contains, "/etc/ssh/ssh_host_*_key.pub r,\n")
+	c.Check(snippet, testutil.Contains, "/etc/ssh/ssh_host_*_key r,\n")
+	c.Check(snippet, Not(testutil.Contains), "w,")
+
+	// connected plugs have no seccomp snippet
+	seccompSpec := &seccomp.Specification{}
+	err = seccompSpec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil)
+	c.Assert(err, IsNil)
+	c.Assert(seccompSpec.SecurityTags(), HasLen, 0)
+}
+
+func (s *SshKeysInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
@@ -0,0 +1,87 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/snapcore/snapd/interfaces"
+)
+
+const sysfsObserveSummary = `allows reading the sysfs subtree named by the slot`
+
+const sysfsObserveBaseDeclarationSlots = `
+  sysfs-observe:
+    allow-installation:
+      slot-snap-type:
+        - core
+        - gadget
+    deny-auto-connection: true
+`
+
+// Pattern to match the paths under /sys that can be given as the path
+// attribute, without anything apparmor would treat as a glob
+var sysfsObservePathPattern = regexp.MustCompile(`^/sys(/[A-Za-z0-9:@_.+-]+)+$`)
+
+// sysfsObservePath returns the path attribute of the slot, checking
+// that it is a path under /sys.
+func sysfsObservePath(slot *interfaces.Slot) (string, error) {
+	path, ok := slot.Attrs["path"].(string)
+	if !ok || path == "" {
+		return "", fmt.Errorf("sysfs-observe slot must have a path attribute")
+	}
+	if filepath.Clean(path) != path || !sysfsObservePathPattern.MatchString(path) {
+		return "", fmt.Errorf("sysfs-observe path attribute must be a clean path under /sys: %q", path)
+	}
+	return path, nil
+}
+
+func sysfsObserveConnectedPlugAppArmor(slot *interfaces.Slot) string {
+	path, err := sysfsObservePath(slot)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(`
+# Description: Allow reading the sysfs subtree named by the slot. As apparmor
+# resolves symlinks, the slot needs to name the real path of the subtree,
+# usually under /sys/devices.
+%s{,/,/**} r,
+`, path)
+}
+
+func init() {
+	var iface *commonInterface
+	iface = &commonInterface{
+		name:                 "sysfs-observe",
+		summary:              sysfsObserveSummary,
+		baseDeclarationSlots: sysfsObserveBaseDeclarationSlots,
+		sanitizeSlot: func(slot *interfaces.Slot) error {
+			if err := sanitizeSlotReservedForOSOrGadget(iface, slot); err != nil {
+				return err
+			}
+			_, err := sysfsObservePath(slot)
+			return err
+		},
+		connectedPlugAppArmorForSlot: sysfsObserveConnectedPlugAppArmor,
+	}
+	registerIface(iface)
+}
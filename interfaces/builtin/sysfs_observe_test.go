@@ -0,0 +1,170 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type SysfsObserveInterfaceSuite struct {
+	iface interfaces.Interface
+
+	// Core Snap
+	slot *interfaces.Slot
+
+	// Gadget Snap
+	gadgetSlot *interfaces.Slot
+
+	// App Snap
+	appSlot *interfaces.Slot
+
+	// Consuming Snap
+	plug *interfaces.Plug
+}
+
+var _ = Suite(&SysfsObserveInterfaceSuite{
+	iface: builtin.MustInterface("sysfs-observe"),
+})
+
+func (s *SysfsObserveInterfaceSuite) SetUpTest(c *C) {
+	coreSnapInfo := snaptest.MockInfo(c, `
+name: core
+type: os
+slots:
+    sensor:
+        interface: sysfs-observe
+        path: /sys/devices/platform/soc/3f804000.i2c
+`, nil)
+	s.slot = &interfaces.Slot{SlotInfo: coreSnapInfo.Slots["sensor"]}
+
+	gadgetSnapInfo := snaptest.MockInfo(c, `
+name: some-device
+type: gadget
+slots:
+    fan:
+        interface: sysfs-observe
+        path: /sys/devices/platform/pwm-fan
+`, nil)
+	s.gadgetSlot = &interfaces.Slot{SlotInfo: gadgetSnapInfo.Slots["fan"]}
+
+	appSnapInfo := snaptest.MockInfo(c, `
+name: some-app
+slots:
+    sensor:
+        interface: sysfs-observe
+        path: /sys/devices/platform/soc/3f804000.i2c
+`, nil)
+	s.appSlot = &interfaces.Slot{SlotInfo: appSnapInfo.Slots["sensor"]}
+
+	consumingSnapInfo := snaptest.MockInfo(c, `
+name: client-snap
+apps:
+    app-accessing-sysfs:
+        command: foo
+        plugs: [sysfs-observe]
+`, nil)
+	s.plug = &interfaces.Plug{PlugInfo: consumingSnapInfo.Plugs["sysfs-observe"]}
+}
+
+func (s *SysfsObserveInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "sysfs-observe")
+}
+
+func (s *SysfsObserveInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(s.slot.Sanitize(s.iface), IsNil)
+	c.Assert(s.gadgetSlot.Sanitize(s.iface), IsNil)
+	c.Assert(s.appSlot.Sanitize(s.iface), ErrorMatches,
+		"sysfs-observe slots are reserved for the core and gadget snaps")
+}
+
+func (s *SysfsObserveInterfaceSuite) TestSanitizeSlotBadAttrs(c *C) {
+	for _, t := range []struct {
+		attrs map[string]interface{}
+		err   string
+	}{
+		{nil, "sysfs-observe slot must have a path attribute"},
+		{map[string]interface{}{"path": ""}, "sysfs-observe slot must have a path attribute"},
+		{map[string]interface{}{"path": 1}, "sysfs-observe slot must have a path attribute"},
+		{map[string]interface{}{"path": "/sys"}, `sysfs-observe path attribute must be a clean path under /sys: "/sys"`},
+		{map[string]interface{}{"path": "/sys/"}, `sysfs-observe path attribute must be a clean path under /sys: "/sys/"`},
+		{map[string]interface{}{"path": "/sys/../etc"}, `sysfs-observe path attribute must be a clean path under /sys: "/sys/../etc"`},
+		{map[string]interface{}{"path": "/sys/class/../../etc"}, `sysfs-observe path attribute must be a clean path under /sys: .*`},
+		{map[string]interface{}{"path": "/sys/devices/"}, `sysfs-observe path attribute must be a clean path under /sys: .*`},
+		{map[string]interface{}{"path": "/sysfoo/bar"}, `sysfs-observe path attribute must be a clean path under /sys: .*`},
+		{map[string]interface{}{"path": "/proc/sys/kernel"}, `sysfs-observe path attribute must be a clean path under /sys: .*`},
+		{map[string]interface{}{"path": "sys/devices"}, `sysfs-observe path attribute must be a clean path under /sys: .*`},
+		{map[string]interface{}{"path": "/sys/devices/*"}, `sysfs-observe path attribute must be a clean path under /sys: .*`},
+		{map[string]interface{}{"path": "/sys/devices/{platform,virtual}"}, `sysfs-observe path attribute must be a clean path under /sys: .*`},
+		{map[string]interface{}{"path": "/sys/devices r,\n/etc/shadow"}, `(?s)sysfs-observe path attribute must be a clean path under /sys: .*`},
+	} {
+		slot := &interfaces.Slot{SlotInfo: &snap.SlotInfo{
+			Snap:      s.slot.Snap,
+			Name:      "sensor",
+			Interface: "sysfs-observe",
+			Attrs:     t.attrs,
+		}}
+		c.Check(slot.Sanitize(s.iface), ErrorMatches, t.err, Commentf("%v", t.attrs))
+	}
+}
+
+func (s *SysfsObserveInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(s.plug.Sanitize(s.iface), IsNil)
+}
+
+func (s *SysfsObserveInterfaceSuite) TestAppArmorSpec(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.client-snap.app-accessing-sysfs"})
+	snippet := spec.SnippetForTag("snap.client-snap.app-accessing-sysfs")
+	c.Check(snippet, testutil.Contains, "\n/sys/devices/platform/soc/3f804000.i2c{,/,/**} r,\n")
+	// read-only access
+	c.Check(snippet, Not(testutil.Contains), " rw,")
+
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.gadgetSlot, nil), IsNil)
+	snippet = spec.SnippetForTag("snap.client-snap.app-accessing-sysfs")
+	c.Check(snippet, testutil.Contains, "\n/sys/devices/platform/pwm-fan{,/,/**} r,\n")
+}
+
+func (s *SysfsObserveInterfaceSuite) TestSecCompSpec(c *C) {
+	spec := &seccomp.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
+	c.Assert(spec.SecurityTags(), HasLen, 0)
+}
+
+func (s *SysfsObserveInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, false)
+	c.Assert(si.Summary, Equals, "allows reading the sysfs subtree named by the slot")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "sysfs-observe")
+}
+
+func (s *SysfsObserveInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
@@ -0,0 +1,76 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const systemObserveLogindSummary = `allows reading the power state properties of logind`
+
+const systemObserveLogindBaseDeclarationSlots = `
+  system-observe-logind:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const systemObserveLogindConnectedPlugAppArmor = `
+# Description: Can read the properties of the logind manager, like whether the
+# system is preparing for sleep and the operations blocked by inhibitors. This
+# does not allow calling any logind method, so there is no control over the
+# power state; see
+# https://www.freedesktop.org/wiki/Software/systemd/logind/
+
+#include <abstractions/dbus-strict>
+
+# Introspection of org.freedesktop.login1
+dbus (send)
+    bus=system
+    path=/org/freedesktop/login1
+    interface=org.freedesktop.DBus.Introspectable
+    member=Introspect
+    peer=(label=unconfined),
+
+# Read all properties from login1
+dbus (send)
+    bus=system
+    path=/org/freedesktop/login1
+    interface=org.freedesktop.DBus.Properties
+    member=Get{,All}
+    peer=(label=unconfined),
+
+# Receive login1 property changed events
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/login1
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged
+    peer=(label=unconfined),
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "system-observe-logind",
+		summary:               systemObserveLogindSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  systemObserveLogindBaseDeclarationSlots,
+		connectedPlugAppArmor: systemObserveLogindConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
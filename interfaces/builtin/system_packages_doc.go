@@ -0,0 +1,68 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"github.com/snapcore/snapd/interfaces/mount"
+)
+
+const systemPackagesDocSummary = `allows read access to the documentation of system packages`
+
+const systemPackagesDocBaseDeclarationSlots = `
+  system-packages-doc:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const systemPackagesDocConnectedPlugAppArmor = `
+# Description: Can read the documentation of packages installed on the
+# host system. Documentation directories commonly symlink to each other,
+# so both are allowed in full.
+
+# the host documentation bind mounted over the one of the base snap
+/usr/share/doc/ r,
+/usr/share/doc/** r,
+
+/var/lib/snapd/hostfs/usr/share/doc/ r,
+/var/lib/snapd/hostfs/usr/share/doc/** r,
+/var/lib/snapd/hostfs/usr/share/help/ r,
+/var/lib/snapd/hostfs/usr/share/help/** r,
+`
+
+// the host documentation is bind mounted where applications look for it
+var systemPackagesDocConnectedPlugMount = []mount.Entry{{
+	Name:    "/var/lib/snapd/hostfs/usr/share/doc",
+	Dir:     "/usr/share/doc",
+	Options: []string{"bind", "ro"},
+}}
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "system-packages-doc",
+		summary:               systemPackagesDocSummary,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  systemPackagesDocBaseDeclarationSlots,
+		connectedPlugAppArmor: systemPackagesDocConnectedPlugAppArmor,
+		connectedPlugMount:    systemPackagesDocConnectedPlugMount,
+		reservedForOS:         true,
+	})
+}
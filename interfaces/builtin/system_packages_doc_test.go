@@ -0,0 +1,111 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type SystemPackagesDocInterfaceSuite struct {
+	iface interfaces.Interface
+	slot  *interfaces.Slot
+	plug  *interfaces.Plug
+}
+
+var _ = Suite(&SystemPackagesDocInterfaceSuite{
+	iface: builtin.MustInterface("system-packages-doc"),
+})
+
+func (s *SystemPackagesDocInterfaceSuite) SetUpTest(c *C) {
+	var mockPlugSnapInfoYaml = `name: other
+version: 1.0
+apps:
+ app:
+  command: foo
+  plugs: [system-packages-doc]
+`
+	s.slot = &interfaces.Slot{
+		SlotInfo: &snap.SlotInfo{
+			Snap:      &snap.Info{SuggestedName: "core", Type: snap.TypeOS},
+			Name:      "system-packages-doc",
+			Interface: "system-packages-doc",
+		},
+	}
+	snapInfo := snaptest.MockInfo(c, mockPlugSnapInfoYaml, nil)
+	s.plug = &interfaces.Plug{PlugInfo: snapInfo.Plugs["system-packages-doc"]}
+}
+
+func (s *SystemPackagesDocInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "system-packages-doc")
+}
+
+func (s *SystemPackagesDocInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(s.slot.Sanitize(s.iface), IsNil)
+	slot := &interfaces.Slot{SlotInfo: &snap.SlotInfo{
+		Snap:      &snap.Info{SuggestedName: "some-snap"},
+		Name:      "system-packages-doc",
+		Interface: "system-packages-doc",
+	}}
+	c.Assert(slot.Sanitize(s.iface), ErrorMatches,
+		"system-packages-doc slots are reserved for the core snap")
+}
+
+func (s *SystemPackagesDocInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(s.plug.Sanitize(s.iface), IsNil)
+}
+
+func (s *SystemPackagesDocInterfaceSuite) TestUsedSecuritySystems(c *C) {
+	// connected plugs have a non-nil security snippet for apparmor
+	apparmorSpec := &apparmor.Specification{}
+	err := apparmorSpec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil)
+	c.Assert(err, IsNil)
+	c.Assert(apparmorSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
+	c.Assert(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, "/var/lib/snapd/hostfs/usr/share/doc/** r,")
+	c.Assert(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, "/var/lib/snapd/hostfs/usr/share/help/** r,")
+	c.Assert(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, "/usr/share/doc/** r,")
+
+	// connected plugs get the host documentation bind mounted
+	mountSpec := &mount.Specification{}
+	err = mountSpec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil)
+	c.Assert(err, IsNil)
+	c.Check(mountSpec.MountEntries(), DeepEquals, []mount.Entry{{
+		Name:    "/var/lib/snapd/hostfs/usr/share/doc",
+		Dir:     "/usr/share/doc",
+		Options: []string{"bind", "ro"},
+	}})
+}
+
+func (s *SystemPackagesDocInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, true)
+}
+
+func (s *SystemPackagesDocInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
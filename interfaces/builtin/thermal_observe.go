@@ -0,0 +1,58 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+const thermalObserveSummary = `allows reading the temperatures of the thermal zones`
+
+const thermalObserveBaseDeclarationSlots = `
+  thermal-observe:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const thermalObserveConnectedPlugAppArmor = `
+# Description: Can read the temperatures and trip points of the thermal zones
+# and the state of the cooling devices. Access is read-only.
+# https://www.kernel.org/doc/Documentation/thermal/sysfs-api.txt
+
+/sys/class/thermal/ r,
+
+# The entries in /sys/class/thermal are symlinks to the devices
+/sys/devices/virtual/thermal/ r,
+/sys/devices/virtual/thermal/thermal_zone[0-9]*/ r,
+/sys/devices/virtual/thermal/thermal_zone[0-9]*/{type,temp,mode,policy,available_policies} r,
+/sys/devices/virtual/thermal/thermal_zone[0-9]*/trip_point_[0-9]*_{type,temp,hyst} r,
+/sys/devices/virtual/thermal/cooling_device[0-9]*/ r,
+/sys/devices/virtual/thermal/cooling_device[0-9]*/{type,cur_state,max_state} r,
+`
+
+func init() {
+	registerIface(&commonInterface{
+		name:                  "thermal-observe",
+		summary:               thermalObserveSummary,
+		implicitOnCore:        true,
+		implicitOnClassic:     true,
+		baseDeclarationSlots:  thermalObserveBaseDeclarationSlots,
+		connectedPlugAppArmor: thermalObserveConnectedPlugAppArmor,
+		reservedForOS:         true,
+	})
+}
@@ -19,6 +19,15 @@
 
 package builtin
 
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+)
+
 const tpmSummary = `allows access to the Trusted Platform Module device`
 
 const tpmBaseDeclarationSlots = `
@@ -26,26 +35,86 @@ const tpmBaseDeclarationSlots = `
     allow-installation:
       slot-snap-type:
         - core
+        - gadget
     deny-auto-connection: true
 `
 
 const tpmConnectedPlugAppArmor = `
 # Description: for those who need to talk to the system TPM chip over /dev/tpm0
+# and the kernel resource manager over /dev/tpmrm0
 
 /dev/tpm0 rw,
+/dev/tpmrm0 rw,
 `
 
-const tpmConnectedPlugUDev = `KERNEL=="tpm[0-9]*", TAG+="###CONNECTED_SECURITY_TAGS###"`
+const tpmConnectedPlugUDev = `KERNEL=="tpm[0-9]*", TAG+="###CONNECTED_SECURITY_TAGS###"
+KERNEL=="tpmrm[0-9]*", TAG+="###CONNECTED_SECURITY_TAGS###"`
+
+// Pattern to match the character device of a TPM chip
+var tpmDevicePattern = regexp.MustCompile(`^/dev/tpm[0-9]{1,3}$`)
+
+// tpmDevice returns the TPM device selected by the optional path
+// attribute of the slot, or "" when the slot doesn't select one.
+func tpmDevice(slot *interfaces.Slot) (string, error) {
+	path, ok := slot.Attrs["path"]
+	if !ok {
+		return "", nil
+	}
+	device, ok := path.(string)
+	if !ok || filepath.Clean(device) != device || !tpmDevicePattern.MatchString(device) {
+		return "", fmt.Errorf("tpm path attribute must be a TPM device: %v", path)
+	}
+	return device, nil
+}
+
+func tpmConnectedPlugAppArmorForSlot(slot *interfaces.Slot) string {
+	device, err := tpmDevice(slot)
+	if err != nil {
+		return ""
+	}
+	if device == "" {
+		return tpmConnectedPlugAppArmor
+	}
+	num := strings.TrimPrefix(device, "/dev/tpm")
+	return fmt.Sprintf(`
+# Description: for those who need to talk to the TPM chip selected by the
+# slot over %[1]s and the kernel resource manager over /dev/tpmrm%[2]s
+
+%[1]s rw,
+/dev/tpmrm%[2]s rw,
+`, device, num)
+}
+
+func tpmConnectedPlugUDevForSlot(slot *interfaces.Slot) string {
+	device, err := tpmDevice(slot)
+	if err != nil {
+		return ""
+	}
+	if device == "" {
+		return tpmConnectedPlugUDev
+	}
+	num := strings.TrimPrefix(device, "/dev/tpm")
+	return fmt.Sprintf(`KERNEL=="tpm%[1]s", TAG+="###CONNECTED_SECURITY_TAGS###"
+KERNEL=="tpmrm%[1]s", TAG+="###CONNECTED_SECURITY_TAGS###"`, num)
+}
 
 func init() {
-	registerIface(&commonInterface{
-		name:                  "tpm",
-		summary:               tpmSummary,
-		implicitOnCore:        true,
-		implicitOnClassic:     true,
-		baseDeclarationSlots:  tpmBaseDeclarationSlots,
-		connectedPlugAppArmor: tpmConnectedPlugAppArmor,
-		connectedPlugUDev:     tpmConnectedPlugUDev,
-		reservedForOS:         true,
-	})
+	var iface *commonInterface
+	iface = &commonInterface{
+		name:                 "tpm",
+		summary:              tpmSummary,
+		implicitOnCore:       true,
+		implicitOnClassic:    true,
+		baseDeclarationSlots: tpmBaseDeclarationSlots,
+		sanitizeSlot: func(slot *interfaces.Slot) error {
+			if err := sanitizeSlotReservedForOSOrGadget(iface, slot); err != nil {
+				return err
+			}
+			_, err := tpmDevice(slot)
+			return err
+		},
+		connectedPlugAppArmorForSlot: tpmConnectedPlugAppArmorForSlot,
+		connectedPlugUDevForSlot:     tpmConnectedPlugUDevForSlot,
+	}
+	registerIface(iface)
 }
@@ -31,9 +31,10 @@ import (
 )
 
 type TpmInterfaceSuite struct {
-	iface interfaces.Interface
-	slot  *interfaces.Slot
-	plug  *interfaces.Plug
+	iface    interfaces.Interface
+	slot     *interfaces.Slot
+	tpm1Slot *interfaces.Slot
+	plug     *interfaces.Plug
 }
 
 var _ = Suite(&TpmInterfaceSuite{
@@ -52,9 +53,18 @@ slots:
   tpm:
 `
 
+const tpmGadgetYaml = `name: some-device
+type: gadget
+slots:
+  tpm1:
+    interface: tpm
+    path: /dev/tpm1
+`
+
 func (s *TpmInterfaceSuite) SetUpTest(c *C) {
 	s.plug = MockPlug(c, tpmConsumerYaml, nil, "tpm")
 	s.slot = MockSlot(c, tpmCoreYaml, nil, "tpm")
+	s.tpm1Slot = MockSlot(c, tpmGadgetYaml, nil, "tpm1")
 }
 
 func (s *TpmInterfaceSuite) TestName(c *C) {
@@ -69,7 +79,22 @@ func (s *TpmInterfaceSuite) TestSanitizeSlot(c *C) {
 		Interface: "tpm",
 	}}
 	c.Assert(slot.Sanitize(s.iface), ErrorMatches,
-		"tpm slots are reserved for the core snap")
+		"tpm slots are reserved for the core and gadget snaps")
+}
+
+func (s *TpmInterfaceSuite) TestSanitizeSlotPath(c *C) {
+	c.Assert(s.tpm1Slot.Sanitize(s.iface), IsNil)
+	for _, path := range []interface{}{
+		"", "/dev/tpm", "/dev/tpmrm0", "/dev/tpm*", "/dev/../dev/tpm0", "/dev/sda", 1,
+	} {
+		slot := &interfaces.Slot{SlotInfo: &snap.SlotInfo{
+			Snap:      &snap.Info{SuggestedName: "core", Type: snap.TypeOS},
+			Name:      "tpm",
+			Interface: "tpm",
+			Attrs:     map[string]interface{}{"path": path},
+		}}
+		c.Check(slot.Sanitize(s.iface), ErrorMatches, `tpm path attribute must be a TPM device: .*`, Commentf("path %v", path))
+	}
 }
 
 func (s *TpmInterfaceSuite) TestSanitizePlug(c *C) {
@@ -80,7 +105,17 @@ func (s *TpmInterfaceSuite) TestAppArmorSpec(c *C) {
 	spec := &apparmor.Specification{}
 	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
 	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
-	c.Assert(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "/dev/tpm0")
+	c.Assert(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "\n/dev/tpm0 rw,\n")
+	c.Assert(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "\n/dev/tpmrm0 rw,\n")
+}
+
+func (s *TpmInterfaceSuite) TestAppArmorSpecPath(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.tpm1Slot, nil), IsNil)
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	c.Check(snippet, testutil.Contains, "\n/dev/tpm1 rw,\n")
+	c.Check(snippet, testutil.Contains, "\n/dev/tpmrm1 rw,\n")
+	c.Check(snippet, Not(testutil.Contains), "/dev/tpm0")
 }
 
 func (s *TpmInterfaceSuite) TestUDevSpec(c *C) {
@@ -88,6 +123,15 @@ func (s *TpmInterfaceSuite) TestUDevSpec(c *C) {
 	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
 	c.Assert(spec.Snippets(), HasLen, 1)
 	c.Assert(spec.Snippets()[0], testutil.Contains, `KERNEL=="tpm[0-9]*", TAG+="snap_consumer_app"`)
+	c.Assert(spec.Snippets()[0], testutil.Contains, `KERNEL=="tpmrm[0-9]*", TAG+="snap_consumer_app"`)
+}
+
+func (s *TpmInterfaceSuite) TestUDevSpecPath(c *C) {
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.tpm1Slot, nil), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 1)
+	c.Check(spec.Snippets()[0], Equals, `KERNEL=="tpm1", TAG+="snap_consumer_app"
+KERNEL=="tpmrm1", TAG+="snap_consumer_app"`)
 }
 
 func (s *TpmInterfaceSuite) TestStaticInfo(c *C) {
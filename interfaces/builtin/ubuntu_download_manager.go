@@ -24,15 +24,27 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/snap"
 )
 
 const ubuntuDownloadManagerSummary = `allows operating as or interacting with the Ubuntu download manager`
 
+// connecting to the download manager of the system is up to the user,
+// connecting to one provided by an app is allowed case by case
+const ubuntuDownloadManagerBaseDeclarationPlugs = `
+  ubuntu-download-manager:
+    deny-auto-connection: true
+    deny-connection:
+      slot-snap-type:
+        - app
+`
+
 const ubuntuDownloadManagerBaseDeclarationSlots = `
   ubuntu-download-manager:
     allow-installation:
       slot-snap-type:
         - app
+        - core
     deny-connection: true
 `
 
@@ -71,6 +83,13 @@ dbus (receive)
      path=/com/canonical/applications/download/@{PROFILE_DBUS}/**
      interface=org.freedesktop.DBus.Properties
      peer=(label=###SLOT_SECURITY_TAGS###),
+# monitor the progress of our downloads
+dbus (send)
+     bus=session
+     path=/com/canonical/applications/download/@{PROFILE_DBUS}/**
+     interface=org.freedesktop.DBus.Properties
+     member=Get{,All}
+     peer=(label=###SLOT_SECURITY_TAGS###),
 dbus (receive, send)
      bus=session
      path=/com/canonical/applications/download/@{PROFILE_DBUS}/**
@@ -203,6 +222,8 @@ func (iface *ubuntuDownloadManagerInterface) Name() string {
 func (iface *ubuntuDownloadManagerInterface) StaticInfo() interfaces.StaticInfo {
 	return interfaces.StaticInfo{
 		Summary:              ubuntuDownloadManagerSummary,
+		ImplicitOnClassic:    true,
+		BaseDeclarationPlugs: ubuntuDownloadManagerBaseDeclarationPlugs,
 		BaseDeclarationSlots: ubuntuDownloadManagerBaseDeclarationSlots,
 	}
 }
@@ -213,18 +234,29 @@ func (iface *ubuntuDownloadManagerInterface) String() string {
 
 func (iface *ubuntuDownloadManagerInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.Plug, plugAttrs map[string]interface{}, slot *interfaces.Slot, slotAttrs map[string]interface{}) error {
 	old := "###SLOT_SECURITY_TAGS###"
-	new := slotAppLabelExpr(slot)
+	var new string
+	if slot.Snap.Type == snap.TypeOS {
+		// the download manager of the classic system runs unconfined
+		new = "unconfined"
+	} else {
+		new = slotAppLabelExpr(slot)
+	}
 	snippet := strings.Replace(downloadConnectedPlugAppArmor, old, new, -1)
 	spec.AddSnippet(snippet)
 	return nil
 }
 
 func (iface *ubuntuDownloadManagerInterface) AppArmorPermanentSlot(spec *apparmor.Specification, slot *interfaces.Slot) error {
-	spec.AddSnippet(downloadPermanentSlotAppArmor)
+	if slot.Snap.Type != snap.TypeOS {
+		spec.AddSnippet(downloadPermanentSlotAppArmor)
+	}
 	return nil
 }
 
 func (iface *ubuntuDownloadManagerInterface) AppArmorConnectedSlot(spec *apparmor.Specification, plug *interfaces.Plug, plugAttrs map[string]interface{}, slot *interfaces.Slot, slotAttrs map[string]interface{}) error {
+	if slot.Snap.Type == snap.TypeOS {
+		return nil
+	}
 	old := "###PLUG_SECURITY_TAGS###"
 	new := plugAppLabelExpr(plug)
 	snippet := strings.Replace(downloadConnectedSlotAppArmor, old, new, -1)
@@ -25,6 +25,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/testutil"
@@ -86,6 +87,52 @@ func (s *UbuntuDownloadManagerInterfaceSuite) TestUsedSecuritySystems(c *C) {
 	c.Assert(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, "path=/com/canonical/applications/download/**")
 }
 
+func (s *UbuntuDownloadManagerInterfaceSuite) TestAppArmorSpec(c *C) {
+	appSlot := MockSlot(c, `name: udm
+apps:
+ udm:
+  command: foo
+  slots: [ubuntu-download-manager]
+`, nil, "ubuntu-download-manager")
+
+	// with the slot coming from an app snap, also on classic
+	restore := release.MockOnClassic(true)
+	defer restore()
+
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, appSlot, nil), IsNil)
+	snippet := spec.SnippetForTag("snap.other.app")
+	c.Check(snippet, testutil.Contains, `peer=(label="snap.udm.udm"),`)
+	c.Check(snippet, testutil.Contains, "member=createDownload\n")
+	c.Check(snippet, testutil.Contains, "member=Get{,All}\n")
+
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedSlot(s.iface, s.plug, nil, appSlot, nil), IsNil)
+	c.Assert(spec.AddPermanentSlot(s.iface, appSlot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.udm.udm"})
+	c.Check(spec.SnippetForTag("snap.udm.udm"), testutil.Contains, `peer=(label="snap.other.app"),`)
+	c.Check(spec.SnippetForTag("snap.udm.udm"), testutil.Contains, `name="com.canonical.applications.Downloader"`)
+
+	// with the slot coming from the core snap on classic
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, nil, s.slot, nil), IsNil)
+	c.Check(spec.SnippetForTag("snap.other.app"), testutil.Contains, "peer=(label=unconfined),")
+
+	spec = &apparmor.Specification{}
+	c.Assert(spec.AddConnectedSlot(s.iface, s.plug, nil, s.slot, nil), IsNil)
+	c.Assert(spec.AddPermanentSlot(s.iface, s.slot), IsNil)
+	c.Check(spec.SecurityTags(), HasLen, 0)
+}
+
+func (s *UbuntuDownloadManagerInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, true)
+	c.Assert(si.Summary, Equals, "allows operating as or interacting with the Ubuntu download manager")
+	c.Assert(si.BaseDeclarationPlugs, testutil.Contains, "deny-auto-connection: true")
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "deny-connection: true")
+}
+
 func (s *UbuntuDownloadManagerInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
@@ -593,6 +593,10 @@ func (iface *unity7Interface) SecCompConnectedPlug(spec *seccomp.Specification,
 	return nil
 }
 
+func (iface *unity7Interface) isReservedForOS() bool {
+	return true
+}
+
 func (iface *unity7Interface) SanitizeSlot(slot *interfaces.Slot) error {
 	return sanitizeSlotReservedForOS(iface, slot)
 }
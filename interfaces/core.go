@@ -175,6 +175,16 @@ type SlotSanitizer interface {
 	SanitizeSlot(slot *Slot) error
 }
 
+// PlugDisconnecter can be implemented by Interfaces that need to clean up after their plugs get disconnected.
+type PlugDisconnecter interface {
+	DisconnectPlug(plug *Plug, slot *Slot) error
+}
+
+// SlotDisconnecter can be implemented by Interfaces that need to clean up after their slots get disconnected.
+type SlotDisconnecter interface {
+	DisconnectSlot(plug *Plug, slot *Slot) error
+}
+
 // StaticInfo describes various static-info of a given interface.
 //
 // The Summary must be a one-line string of length suitable for listing views.
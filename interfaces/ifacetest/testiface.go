@@ -43,6 +43,11 @@ type TestInterface struct {
 	// SanitizeSlotCallback is the callback invoked inside SanitizeSlot()
 	SanitizeSlotCallback func(slot *interfaces.Slot) error
 
+	// DisconnectPlugCallback is the callback invoked inside DisconnectPlug()
+	DisconnectPlugCallback func(plug *interfaces.Plug, slot *interfaces.Slot) error
+	// DisconnectSlotCallback is the callback invoked inside DisconnectSlot()
+	DisconnectSlotCallback func(plug *interfaces.Plug, slot *interfaces.Slot) error
+
 	ValidatePlugCallback func(plug *interfaces.Plug, attrs map[string]interface{}) error
 	ValidateSlotCallback func(slot *interfaces.Slot, attrs map[string]interface{}) error
 
@@ -133,6 +138,22 @@ func (t *TestInterface) SanitizeSlot(slot *interfaces.Slot) error {
 	return nil
 }
 
+// DisconnectPlug cleans up after the plug got disconnected from the slot.
+func (t *TestInterface) DisconnectPlug(plug *interfaces.Plug, slot *interfaces.Slot) error {
+	if t.DisconnectPlugCallback != nil {
+		return t.DisconnectPlugCallback(plug, slot)
+	}
+	return nil
+}
+
+// DisconnectSlot cleans up after the slot got disconnected from the plug.
+func (t *TestInterface) DisconnectSlot(plug *interfaces.Plug, slot *interfaces.Slot) error {
+	if t.DisconnectSlotCallback != nil {
+		return t.DisconnectSlotCallback(plug, slot)
+	}
+	return nil
+}
+
 func (t *TestInterface) ValidatePlug(plug *interfaces.Plug, attrs map[string]interface{}) error {
 	if t.ValidatePlugCallback != nil {
 		return t.ValidatePlugCallback(plug, attrs)
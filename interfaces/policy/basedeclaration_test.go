@@ -153,7 +153,6 @@ func (s *baseDeclSuite) TestAutoConnection(c *C) {
 		"optical-drive":           true,
 		"pulseaudio":              true,
 		"screen-inhibit-control":  true,
-		"ubuntu-download-manager": true,
 		"unity7":                  true,
 		"unity8":                  true,
 		"upower-observe":          true,
@@ -461,11 +460,13 @@ var (
 		"autopilot-introspection": {"core"},
 		"avahi-control":           {"app", "core"},
 		"avahi-observe":           {"app", "core"},
+		"block-devices":           {"core", "gadget"},
 		"bluez":                   {"app", "core"},
 		"bool-file":               {"core", "gadget"},
 		"browser-support":         {"core"},
 		"content":                 {"app", "gadget"},
 		"core-support":            {"core"},
+		"custom-device":           {"core", "gadget"},
 		"dbus":                    {"app"},
 		"docker-support":          {"core"},
 		"fwupd":                   {"app"},
@@ -487,13 +488,19 @@ var (
 		"network-status":          {"app"},
 		"ofono":                   {"app", "core"},
 		"online-accounts-service": {"app"},
-		"ppp":         {"core"},
-		"pulseaudio":  {"app", "core"},
-		"serial-port": {"core", "gadget"},
-		"spi":         {"core", "gadget"},
+		"opengl-by-device":        {"core", "gadget"},
+		"ppp":           {"core"},
+		"pwm":           {"core", "gadget"},
+		"pulseaudio":    {"app", "core"},
+		"raw-usb-by-id": {"core", "gadget"},
+		"raw-volume":    {"core", "gadget"},
+		"serial-port":   {"core", "gadget"},
+		"spi":           {"core", "gadget"},
 		"storage-framework-service": {"app"},
+		"sysfs-observe":             {"core", "gadget"},
 		"thumbnailer-service":       {"app"},
-		"ubuntu-download-manager":   {"app"},
+		"tpm":                       {"core", "gadget"},
+		"ubuntu-download-manager":   {"app", "core"},
 		"udisks2":                   {"app"},
 		"uhid":                      {"core"},
 		"unity8":                    {"app"},
@@ -653,6 +660,53 @@ func (s *baseDeclSuite) TestConnection(c *C) {
 	}
 }
 
+func (s *baseDeclSuite) TestConnectionUbuntuDownloadManager(c *C) {
+	for _, onClassic := range []bool{true, false} {
+		restore := release.MockOnClassic(onClassic)
+		defer restore()
+
+		// connecting to an app slot needs to be allowed case by case
+		cand := s.connectCand(c, "ubuntu-download-manager", "", "")
+		c.Check(cand.Check(), NotNil)
+
+		// connecting to the implicit core slot is fine
+		cand = s.connectCand(c, "ubuntu-download-manager", `name: core
+type: os
+slots:
+  ubuntu-download-manager:
+`, "")
+		c.Check(cand.Check(), IsNil)
+		// but not automatically
+		c.Check(cand.CheckAutoConnect(), NotNil)
+	}
+}
+
+func (s *baseDeclSuite) TestConnectionSshKeys(c *C) {
+	// the public host keys can be read by connecting manually
+	cand := s.connectCand(c, "ssh-keys", "", "")
+	c.Check(cand.Check(), IsNil)
+	c.Check(cand.CheckAutoConnect(), NotNil)
+
+	// the private ones need to be allowed case by case
+	cand = s.connectCand(c, "ssh-keys", "", `name: plug-snap
+plugs:
+  ssh-keys:
+    private-keys: true
+`)
+	err := cand.Check()
+	c.Check(err, ErrorMatches, "connection denied by plug rule of interface \"ssh-keys\"")
+
+	plugsSlots := `
+plugs:
+  ssh-keys:
+    allow-connection: true
+`
+
+	snapDecl := s.mockSnapDecl(c, "plug-snap", "J60k4JY0HppjwOjW8dZdYc8obXKxujRu", "canonical", plugsSlots)
+	cand.PlugSnapDeclaration = snapDecl
+	c.Check(cand.Check(), IsNil)
+}
+
 func (s *baseDeclSuite) TestConnectionOnClassic(c *C) {
 	restore := release.MockOnClassic(false)
 	defer restore()
@@ -698,15 +752,17 @@ func (s *baseDeclSuite) TestSanity(c *C) {
 	// given how the rules work this can be delicate,
 	// listed here to make sure that was a conscious decision
 	bothSides := map[string]bool{
-		"classic-support":       true,
-		"core-support":          true,
-		"docker-support":        true,
-		"greengrass-support":    true,
-		"kernel-module-control": true,
-		"kubernetes-support":    true,
-		"lxd-support":           true,
-		"snapd-control":         true,
-		"unity8":                true,
+		"classic-support":         true,
+		"core-support":            true,
+		"docker-support":          true,
+		"greengrass-support":      true,
+		"kernel-module-control":   true,
+		"kubernetes-support":      true,
+		"lxd-support":             true,
+		"snapd-control":           true,
+		"ssh-keys":                true,
+		"ubuntu-download-manager": true,
+		"unity8":                  true,
 	}
 
 	for _, iface := range all {
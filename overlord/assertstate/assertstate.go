@@ -31,6 +31,7 @@ import (
 	"github.com/snapcore/snapd/asserts/snapasserts"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/storestate"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
 )
@@ -101,6 +102,30 @@ func (b *Batch) AddStream(r io.Reader) ([]*asserts.Ref, error) {
 
 // Commit adds the batch of assertions to the system assertion database.
 func (b *Batch) Commit(st *state.State) error {
+	return b.commit(st, nil)
+}
+
+// CommitFetchingMissing adds the batch of assertions to the system
+// assertion database like Commit, but retrieves from the store any
+// prerequisite assertions missing both from the batch and the system
+// assertion database.
+func (b *Batch) CommitFetchingMissing(st *state.State, userID int) error {
+	user, err := userFromUserID(st, userID)
+	if err != nil {
+		return err
+	}
+
+	sto := storestate.Store(st)
+
+	fetchMissing := func(ref *asserts.Ref) (asserts.Assertion, error) {
+		st.Unlock()
+		defer st.Lock()
+		return sto.Assertion(ref.Type, ref.PrimaryKey, user)
+	}
+	return b.commit(st, fetchMissing)
+}
+
+func (b *Batch) commit(st *state.State, fetchMissing func(*asserts.Ref) (asserts.Assertion, error)) error {
 	db := cachedDB(st)
 	retrieve := func(ref *asserts.Ref) (asserts.Assertion, error) {
 		a, err := b.bs.Get(ref.Type, ref.PrimaryKey, ref.Type.MaxSupportedFormat())
@@ -108,6 +133,10 @@ func (b *Batch) Commit(st *state.State) error {
 			// fallback to pre-existing assertions
 			a, err = ref.Resolve(db.Find)
 		}
+		if asserts.IsNotFound(err) && fetchMissing != nil {
+			// and then to the store
+			a, err = fetchMissing(ref)
+		}
 		if err != nil {
 			return nil, findError("cannot find %s", ref, err)
 		}
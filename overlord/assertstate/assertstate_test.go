@@ -204,6 +204,42 @@ func (s *assertMgrSuite) TestBatchConsiderPreexisting(c *C) {
 	c.Check(devAcct.(*asserts.Account).Username(), Equals, "developer1")
 }
 
+func (s *assertMgrSuite) TestBatchCommitFetchingMissing(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// the store key is missing
+	batch := assertstate.NewBatch()
+	err := batch.Add(s.dev1Acct)
+	c.Assert(err, IsNil)
+
+	err = batch.CommitFetchingMissing(s.state, 0)
+	c.Assert(err, IsNil)
+
+	db := assertstate.DB(s.state)
+	devAcct, err := db.Find(asserts.AccountType, map[string]string{
+		"account-id": s.dev1Acct.AccountID(),
+	})
+	c.Assert(err, IsNil)
+	c.Check(devAcct.(*asserts.Account).Username(), Equals, "developer1")
+	_, err = db.Find(asserts.AccountKeyType, map[string]string{
+		"public-key-sha3-384": s.storeSigning.StoreAccountKey("").PublicKeyID(),
+	})
+	c.Check(err, IsNil)
+}
+
+func (s *assertMgrSuite) TestBatchCommitMissingPrerequisite(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	batch := assertstate.NewBatch()
+	err := batch.Add(s.dev1Acct)
+	c.Assert(err, IsNil)
+
+	err = batch.Commit(s.state)
+	c.Assert(err, ErrorMatches, "cannot find account-key .*")
+}
+
 func (s *assertMgrSuite) TestBatchAddStreamReturnsEffectivelyAddedRefs(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -76,10 +76,12 @@ func Manager(s *state.State, hookManager *hookstate.HookManager) (*DeviceManager
 	return m, nil
 }
 
-type prepareDeviceHandler struct{}
+type prepareDeviceHandler struct {
+	context *hookstate.Context
+}
 
 func newPrepareDeviceHandler(context *hookstate.Context) hookstate.Handler {
-	return prepareDeviceHandler{}
+	return prepareDeviceHandler{context: context}
 }
 
 func (h prepareDeviceHandler) Before() error {
@@ -87,6 +89,19 @@ func (h prepareDeviceHandler) Before() error {
 }
 
 func (h prepareDeviceHandler) Done() error {
+	h.context.Lock()
+	defer h.context.Unlock()
+
+	// remember when the device got prepared while seeding, so that
+	// registration does not do it again
+	var seeding bool
+	err := h.context.Get("seeding", &seeding)
+	if err != nil && err != state.ErrNoState {
+		return err
+	}
+	if seeding {
+		h.context.State().Set("device-prepared", true)
+	}
 	return nil
 }
 
@@ -245,8 +260,14 @@ func (m *DeviceManager) ensureOperational() error {
 
 	tasks := []*state.Task{}
 
+	var prepared bool
+	err = m.state.Get("device-prepared", &prepared)
+	if err != nil && err != state.ErrNoState {
+		return err
+	}
+
 	var prepareDevice *state.Task
-	if gadgetInfo != nil && gadgetInfo.Hooks["prepare-device"] != nil {
+	if gadgetInfo != nil && gadgetInfo.Hooks["prepare-device"] != nil && !prepared {
 		summary := i18n.G("Run prepare-device hook")
 		hooksup := &hookstate.HookSetup{
 			Snap: gadgetInfo.Name(),
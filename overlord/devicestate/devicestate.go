@@ -24,6 +24,7 @@ package devicestate
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/logger"
@@ -87,6 +88,16 @@ func Serial(st *state.State) (*asserts.Serial, error) {
 	return a.(*asserts.Serial), nil
 }
 
+// SeedTime returns the time the device finished seeding, or
+// state.ErrNoState if it didn't yet.
+func SeedTime(st *state.State) (time.Time, error) {
+	var seedTime time.Time
+	if err := st.Get("seed-time", &seedTime); err != nil {
+		return time.Time{}, err
+	}
+	return seedTime, nil
+}
+
 // auto-refresh
 func canAutoRefresh(st *state.State) (bool, error) {
 	// we need to be seeded first
@@ -685,6 +685,36 @@ version: gadget
 	c.Check(device.Serial, Equals, "9999")
 }
 
+func (s *deviceMgrSuite) TestDoMarkSeeded(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := devicestate.SeedTime(s.state)
+	c.Check(err, Equals, state.ErrNoState)
+
+	t := s.state.NewTask("mark-seeded", "test")
+	chg := s.state.NewChange("seed", "...")
+	chg.AddTask(t)
+
+	before := time.Now()
+	s.state.Unlock()
+	s.mgr.Ensure()
+	s.mgr.Wait()
+	s.state.Lock()
+	after := time.Now()
+
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+	var seeded bool
+	err = s.state.Get("seeded", &seeded)
+	c.Assert(err, IsNil)
+	c.Check(seeded, Equals, true)
+
+	seedTime, err := devicestate.SeedTime(s.state)
+	c.Assert(err, IsNil)
+	c.Check(seedTime.Before(before), Equals, false)
+	c.Check(seedTime.After(after), Equals, false)
+}
+
 func (s *deviceMgrSuite) TestDoRequestSerialIdempotentAfterGotSerial(c *C) {
 	privKey, _ := assertstest.GenerateKey(testKeyLength)
 
@@ -940,6 +970,86 @@ hooks:
 	c.Check(device.KeyID, Equals, privKey.PublicKey().ID())
 }
 
+func (s *deviceMgrSuite) TestDeviceRegistrationDevicePreparedWhileSeeding(c *C) {
+	r := hookstate.MockRunHook(func(ctx *hookstate.Context, _ *tomb.Tomb) ([]byte, error) {
+		c.Fatalf("unexpected run of hook %q", ctx.HookName())
+		return nil, nil
+	})
+	defer r()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.makeModelAssertionInState(c, "canonical", "pc2", map[string]string{
+		"architecture": "amd64",
+		"kernel":       "pc-kernel",
+		"gadget":       "gadget",
+	})
+
+	s.setupGadget(c, `
+name: gadget
+type: gadget
+version: gadget
+hooks:
+    prepare-device:
+`, "")
+
+	auth.SetDevice(s.state, &auth.DeviceState{
+		Brand: "canonical",
+		Model: "pc2",
+	})
+
+	// the hook ran already while seeding
+	s.state.Set("device-prepared", true)
+
+	s.seeding()
+
+	s.state.Unlock()
+	s.mgr.Ensure()
+	s.state.Lock()
+
+	becomeOperational := s.findBecomeOperationalChange()
+	c.Assert(becomeOperational, NotNil)
+	var kinds []string
+	for _, t := range becomeOperational.Tasks() {
+		kinds = append(kinds, t.Kind())
+	}
+	c.Check(kinds, DeepEquals, []string{"generate-device-key", "request-serial"})
+}
+
+func (s *deviceMgrSuite) TestPrepareDeviceHandlerWhileSeeding(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	hooksup := &hookstate.HookSetup{Snap: "gadget", Hook: "prepare-device"}
+	for _, seeding := range []bool{false, true} {
+		s.state.Set("device-prepared", nil)
+
+		var contextData map[string]interface{}
+		if seeding {
+			contextData = map[string]interface{}{"seeding": true}
+		}
+		task := hookstate.HookTask(s.state, "Run prepare-device hook", hooksup, contextData)
+		ctx, err := hookstate.NewContext(task, s.state, hooksup, nil, "")
+		c.Assert(err, IsNil)
+		handler := devicestate.NewPrepareDeviceHandler(ctx)
+
+		s.state.Unlock()
+		err = handler.Done()
+		s.state.Lock()
+		c.Assert(err, IsNil)
+
+		var prepared bool
+		err = s.state.Get("device-prepared", &prepared)
+		if seeding {
+			c.Assert(err, IsNil)
+			c.Check(prepared, Equals, true)
+		} else {
+			c.Check(err, Equals, state.ErrNoState)
+		}
+	}
+}
+
 func (s *deviceMgrSuite) TestFullDeviceRegistrationErrorBackoff(c *C) {
 	r1 := devicestate.MockKeyLength(testKeyLength)
 	defer r1()
@@ -0,0 +1,97 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package devicestatetest contains helper functions for testing
+// seeding and the device state.
+package devicestatetest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+// MakeSeed materializes a seed in a new directory and returns its
+// path. The seed.yaml lists seedSnaps, with the snap files given by
+// seed snap name in snapFiles copied under snaps/; a seed snap without
+// a file name takes the one of its snap file. The assertions are
+// written as a single stream under assertions/.
+func MakeSeed(c *check.C, seedSnaps []*snap.SeedSnap, snapFiles map[string]string, assertions []asserts.Assertion) string {
+	seedDir := filepath.Join(c.MkDir(), "seed")
+	for _, dir := range []string{"snaps", "assertions"} {
+		err := os.MkdirAll(filepath.Join(seedDir, dir), 0755)
+		c.Assert(err, check.IsNil)
+	}
+
+	// the entries of the caller are left alone
+	entries := make([]*snap.SeedSnap, len(seedSnaps))
+	for i, sn := range seedSnaps {
+		entry := *sn
+		entries[i] = &entry
+		snapFile := snapFiles[entry.Name]
+		if snapFile == "" {
+			continue
+		}
+		if entry.File == "" {
+			entry.File = filepath.Base(snapFile)
+		}
+		err := osutil.CopyFile(snapFile, filepath.Join(seedDir, "snaps", entry.File), osutil.CopyFlagDefault)
+		c.Assert(err, check.IsNil)
+	}
+
+	seedYaml, err := yaml.Marshal(&snap.Seed{Snaps: entries})
+	c.Assert(err, check.IsNil)
+	err = ioutil.WriteFile(filepath.Join(seedDir, "seed.yaml"), seedYaml, 0644)
+	c.Assert(err, check.IsNil)
+
+	WriteAssertions(c, seedDir, "seed.asserts", assertions)
+
+	return seedDir
+}
+
+// WriteAssertions writes the assertions as a single stream to the
+// file fn under the assertions/ directory of the seed.
+func WriteAssertions(c *check.C, seedDir, fn string, assertions []asserts.Assertion) {
+	f, err := os.Create(filepath.Join(seedDir, "assertions", fn))
+	c.Assert(err, check.IsNil)
+	defer f.Close()
+	enc := asserts.NewEncoder(f)
+	for _, a := range assertions {
+		err := enc.Encode(a)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+// MakeSeedSnap builds a snap from snapYaml under the snaps/ directory
+// of the seed and returns its file name.
+func MakeSeedSnap(c *check.C, seedDir, snapYaml string) (fname string) {
+	mockSnapFile := snaptest.MakeTestSnapWithFiles(c, snapYaml, nil)
+	fname = filepath.Base(mockSnapFile)
+	err := os.Rename(mockSnapFile, filepath.Join(seedDir, "snaps", fname))
+	c.Assert(err, check.IsNil)
+	return fname
+}
@@ -0,0 +1,91 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2017 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestatetest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/overlord/devicestate/devicestatetest"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+func TestDeviceStateTest(t *testing.T) { TestingT(t) }
+
+type seedSuite struct{}
+
+var _ = Suite(&seedSuite{})
+
+func (s *seedSuite) TestMakeSeed(c *C) {
+	storeSigning := assertstest.NewStoreStack("can0nical", nil)
+	acct := assertstest.NewAccount(storeSigning, "developer", nil, "")
+
+	fooFile := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 1.0", nil)
+	barFile := snaptest.MakeTestSnapWithFiles(c, "name: bar\nversion: 1.0", nil)
+
+	seedSnaps := []*snap.SeedSnap{
+		{Name: "foo"},
+		{Name: "bar", File: "bar_x1.snap", Unasserted: true},
+	}
+	seedDir := devicestatetest.MakeSeed(c, seedSnaps, map[string]string{
+		"foo": fooFile,
+		"bar": barFile,
+	}, []asserts.Assertion{storeSigning.StoreAccountKey(""), acct})
+
+	// the entries of the caller are left alone
+	c.Check(seedSnaps[0].File, Equals, "")
+
+	seed, err := snap.ReadSeedYaml(filepath.Join(seedDir, "seed.yaml"))
+	c.Assert(err, IsNil)
+	c.Assert(seed.Snaps, HasLen, 2)
+	c.Check(seed.Snaps[0].Name, Equals, "foo")
+	c.Check(seed.Snaps[0].File, Equals, filepath.Base(fooFile))
+	c.Check(seed.Snaps[1].Name, Equals, "bar")
+	c.Check(seed.Snaps[1].File, Equals, "bar_x1.snap")
+	c.Check(seed.Snaps[1].Unasserted, Equals, true)
+
+	for _, sn := range seed.Snaps {
+		snapf, err := snap.Open(filepath.Join(seedDir, "snaps", sn.File))
+		c.Assert(err, IsNil)
+		info, err := snap.ReadInfoFromSnapFile(snapf, nil)
+		c.Assert(err, IsNil)
+		c.Check(info.Name(), Equals, sn.Name)
+	}
+
+	f, err := os.Open(filepath.Join(seedDir, "assertions", "seed.asserts"))
+	c.Assert(err, IsNil)
+	defer f.Close()
+	dec := asserts.NewDecoder(f)
+	var types []string
+	for {
+		a, err := dec.Decode()
+		if err != nil {
+			break
+		}
+		types = append(types, a.Type().Name)
+	}
+	c.Check(types, DeepEquals, []string{"account-key", "account"})
+}
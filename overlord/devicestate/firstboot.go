@@ -20,79 +20,678 @@
 package devicestate
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/snapasserts"
+	"github.com/snapcore/snapd/asserts/sysdb"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/storestate"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
 )
 
 var errNothingToDo = errors.New("nothing to do")
 
-func installSeedSnap(st *state.State, sn *snap.SeedSnap, flags snapstate.Flags) (*state.TaskSet, error) {
+// seedLogf logs a step of seeding, with its details as key=value
+// pairs, so that seeding can be followed in the field.
+func seedLogf(format string, v ...interface{}) {
+	logger.Noticef("seeding: "+format, v...)
+}
+
+// ErrAlreadySeeded is returned when populating the state of a system
+// that is already seeded without asking to reseed it.
+var ErrAlreadySeeded = errors.New("cannot populate state: already seeded")
+
+// seedSnapSideInfo returns the side info for the given seed snap in
+// seedDir, derived from its assertions unless it is unasserted.
+func seedSnapSideInfo(st *state.State, seedDir string, sn *snap.SeedSnap) (*snap.SideInfo, error) {
+	if sn.Unasserted {
+		return &snap.SideInfo{RealName: sn.Name}, nil
+	}
+
+	path := filepath.Join(seedDir, "snaps", sn.File)
+	db := assertstate.DB(st)
+	si, err := snapasserts.DeriveSideInfo(path, db)
+	if asserts.IsNotFound(err) {
+		if hasSnapRevisions(db, sn.Name) {
+			return nil, fmt.Errorf("cannot seed snap %q: file digest does not match assertion", sn.Name)
+		}
+		return nil, fmt.Errorf("cannot find signatures with metadata for snap %q (%q)", sn.Name, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	si.Private = sn.Private
+	si.Contact = sn.Contact
+	return si, nil
+}
+
+// seedSideInfos derives the side infos of seed snaps on demand and
+// only once each, as that involves hashing the snap files.
+type seedSideInfos struct {
+	st      *state.State
+	seedDir string
+	derived map[string]*snap.SideInfo
+	errs    map[string]error
+}
+
+func newSeedSideInfos(st *state.State, seedDir string) *seedSideInfos {
+	return &seedSideInfos{
+		st:      st,
+		seedDir: seedDir,
+		derived: make(map[string]*snap.SideInfo),
+		errs:    make(map[string]error),
+	}
+}
+
+func (s *seedSideInfos) get(sn *snap.SeedSnap) (*snap.SideInfo, error) {
+	if err := s.errs[sn.Name]; err != nil {
+		return nil, err
+	}
+	if sideInfo := s.derived[sn.Name]; sideInfo != nil {
+		return sideInfo, nil
+	}
+	sideInfo, err := seedSnapSideInfo(s.st, s.seedDir, sn)
+	if err != nil {
+		s.errs[sn.Name] = err
+		return nil, err
+	}
+	s.derived[sn.Name] = sideInfo
+	return sideInfo, nil
+}
+
+// installSeedSnap returns the task set to install the seed snap sn
+// using sideInfo as its side info, which is derived from the seed if
+// nil.
+func installSeedSnap(st *state.State, seedDir string, sn *snap.SeedSnap, sideInfo *snap.SideInfo, flags snapstate.Flags) (*state.TaskSet, *snap.Info, error) {
+	path := filepath.Join(seedDir, "snaps", sn.File)
+	if !osutil.FileExists(path) {
+		return nil, nil, fmt.Errorf("cannot seed snap %q: file %q not found", sn.Name, path)
+	}
+
 	if sn.Classic {
 		flags.Classic = true
 	}
 	if sn.DevMode {
 		flags.DevMode = true
 	}
+	if sn.Hold {
+		flags.SkipConfigure = true
+		flags.SkipStartServices = true
+	}
+	if sn.TryMode {
+		if !osutil.IsDirectory(path) {
+			return nil, nil, fmt.Errorf("cannot seed snap %q in try mode: %q is not a directory", sn.Name, path)
+		}
+		flags.TryMode = true
+	}
+
+	if sideInfo == nil {
+		var err error
+		sideInfo, err = seedSnapSideInfo(st, seedDir, sn)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
-	path := filepath.Join(dirs.SnapSeedDir, "snaps", sn.File)
+	snapf, err := snap.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := snap.ReadInfoFromSnapFile(snapf, sideInfo)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	var sideInfo snap.SideInfo
-	if sn.Unasserted {
-		sideInfo.RealName = sn.Name
-	} else {
-		si, err := snapasserts.DeriveSideInfo(path, assertstate.DB(st))
-		if asserts.IsNotFound(err) {
-			return nil, fmt.Errorf("cannot find signatures with metadata for snap %q (%q)", sn.Name, path)
+	ts, err := snapstate.InstallPathWithCohort(st, sideInfo, path, sn.Channel, sn.Cohort, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ts, info, nil
+}
+
+// checkSeedSnapGrade checks that the grade of the model allows
+// seeding sn, only models of grade dangerous (or without a grade) can
+// have unasserted snaps in their seed.
+func checkSeedSnapGrade(model *asserts.Model, sn *snap.SeedSnap) error {
+	if sn.Unasserted && model.Grade() == "signed" {
+		return fmt.Errorf("cannot seed unasserted snap %q with a model of grade %q", sn.Name, model.Grade())
+	}
+	return nil
+}
+
+// checkSeedSnapHold checks that sn is not held if it is one of the
+// snaps the system needs to be set up, info is the information about
+// sn if it could be read.
+func checkSeedSnapHold(model *asserts.Model, sn *snap.SeedSnap, info *snap.Info) error {
+	if !sn.Hold {
+		return nil
+	}
+	essential := sn.Name == "snapd" || sn.Name == "core" || sn.Name == model.Kernel() || sn.Name == model.Gadget()
+	if info != nil && info.Type != snap.TypeApp {
+		essential = true
+	}
+	if essential {
+		return fmt.Errorf("cannot hold essential snap %q", sn.Name)
+	}
+	return nil
+}
+
+// channelRisks are the risk levels a channel can have.
+var channelRisks = []string{"stable", "candidate", "beta", "edge"}
+
+// channelTrack returns the track of the channel, channels not naming
+// one are in the default "latest" track.
+func channelTrack(channel string) string {
+	first := strings.SplitN(channel, "/", 2)[0]
+	if first == "" || strutil.ListContains(channelRisks, first) {
+		return "latest"
+	}
+	return first
+}
+
+// checkSeedSnapChannel checks that the channel of sn is in the track
+// the model requires for it, if any. Only the kernel and the gadget
+// can be constrained by the model.
+func checkSeedSnapChannel(model *asserts.Model, sn *snap.SeedSnap) error {
+	var track string
+	switch sn.Name {
+	case model.Kernel():
+		track = model.KernelTrack()
+	case model.Gadget():
+		track = model.GadgetTrack()
+	}
+	if track == "" {
+		return nil
+	}
+	if channelTrack(sn.Channel) != track {
+		return fmt.Errorf("cannot seed snap %q from channel %q, the model requires track %q", sn.Name, sn.Channel, track)
+	}
+	return nil
+}
+
+// hasSnapRevisions returns whether there are snap-revision assertions
+// for the snap with the given name, meaning that a snap file for it
+// which could not be matched to any of them does not have the
+// expected digest.
+func hasSnapRevisions(db asserts.RODatabase, name string) bool {
+	decls, err := db.FindMany(asserts.SnapDeclarationType, map[string]string{
+		"series":    release.Series,
+		"snap-name": name,
+	})
+	if err != nil {
+		return false
+	}
+	for _, decl := range decls {
+		_, err := db.FindMany(asserts.SnapRevisionType, map[string]string{
+			"snap-id": decl.(*asserts.SnapDeclaration).SnapID(),
+		})
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// setupModelStore points the system to the URL from the store
+// assertion for the store of the model, if there is one.
+func setupModelStore(st *state.State, model *asserts.Model) error {
+	if model.Store() == "" {
+		return nil
+	}
+	a, err := assertstate.DB(st).Find(asserts.StoreType, map[string]string{
+		"store": model.Store(),
+	})
+	if asserts.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	u := a.(*asserts.Store).URL()
+	if u == nil {
+		return nil
+	}
+	return storestate.SetBaseURL(st, u)
+}
+
+// seedSnapInfos returns the information about the seed snaps that
+// can be read from their files, by name, for ordering them.
+func seedSnapInfos(seedDir string, seedSnaps []*snap.SeedSnap) map[string]*snap.Info {
+	infos := make(map[string]*snap.Info, len(seedSnaps))
+	for _, sn := range seedSnaps {
+		snapf, err := snap.Open(filepath.Join(seedDir, "snaps", sn.File))
+		if err != nil {
+			// reported when trying to install it
+			continue
+		}
+		info, err := snap.ReadInfoFromSnapFile(snapf, nil)
+		if err != nil {
+			continue
+		}
+		infos[sn.Name] = info
+	}
+	return infos
+}
+
+// checkSeedKernelAndGadget checks that the kernel and gadget named by
+// the model are the only seed snaps of their type, as otherwise it
+// would be ambiguous which one the device uses.
+func checkSeedKernelAndGadget(seedSnaps []*snap.SeedSnap, infos map[string]*snap.Info, model *asserts.Model) error {
+	for _, t := range []struct {
+		typ  snap.Type
+		name string
+	}{
+		{snap.TypeKernel, model.Kernel()},
+		{snap.TypeGadget, model.Gadget()},
+	} {
+		if t.name == "" {
+			continue
+		}
+		var others []string
+		for _, sn := range seedSnaps {
+			if info := infos[sn.Name]; info != nil && info.Type == t.typ && sn.Name != t.name {
+				others = append(others, strconv.Quote(sn.Name))
+			}
+		}
+		if len(others) != 0 {
+			return fmt.Errorf("cannot seed more than one %s snap: model uses %q but seed also has %s", t.typ, t.name, strings.Join(others, ", "))
 		}
+	}
+	return nil
+}
+
+// seedNeedsCore returns whether seeding the seed snaps needs core:
+// on core devices not seeding the snapd snap core is what the system
+// runs on, otherwise it is only needed as the implicit base of app
+// snaps not using a base of their own.
+func seedNeedsCore(seedSnaps []*snap.SeedSnap, infos map[string]*snap.Info, model *asserts.Model) bool {
+	if len(seedSnaps) == 0 {
+		return false
+	}
+	seedsSnapd := false
+	for _, sn := range seedSnaps {
+		if sn.Name == "snapd" {
+			seedsSnapd = true
+		}
+	}
+	if !model.Classic() && !seedsSnapd {
+		return true
+	}
+	for _, sn := range seedSnaps {
+		info := infos[sn.Name]
+		if info == nil || sn.Name == "snapd" {
+			// problems are reported when trying to install it,
+			// and the snapd snap brings its own runtime
+			continue
+		}
+		if info.Type == snap.TypeApp && info.Base == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGadgetDefaults checks that the configuration defaults of the
+// gadget are all for snaps in the seed or already installed, or for
+// the system, as they are applied when those get configured while
+// seeding.
+func checkGadgetDefaults(st *state.State, seedDir string, gadget *snap.SeedSnap, seedSnaps []*snap.SeedSnap, sideInfos *seedSideInfos) error {
+	snapf, err := snap.Open(filepath.Join(seedDir, "snaps", gadget.File))
+	if err != nil {
+		// reported when trying to install it
+		return nil
+	}
+	ginfo, err := snap.ReadGadgetInfoFromSnapFile(snapf, release.OnClassic)
+	if err != nil || len(ginfo.Defaults) == 0 {
+		return nil
+	}
+
+	snapIDs := map[string]bool{"system": true}
+	for _, sn := range seedSnaps {
+		sideInfo, err := sideInfos.get(sn)
 		if err != nil {
+			return err
+		}
+		if sideInfo.SnapID != "" {
+			snapIDs[sideInfo.SnapID] = true
+		}
+	}
+	// core in particular can be already installed
+	snapStates, err := snapstate.All(st)
+	if err != nil {
+		return err
+	}
+	for _, snapst := range snapStates {
+		if si := snapst.CurrentSideInfo(); si != nil && si.SnapID != "" {
+			snapIDs[si.SnapID] = true
+		}
+	}
+
+	var unknown []string
+	for snapID := range ginfo.Defaults {
+		if !snapIDs[snapID] {
+			unknown = append(unknown, snapID)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("cannot use gadget %q configuration defaults for snaps not in the seed: %s", gadget.Name, strings.Join(unknown, ", "))
+}
+
+// contentTag returns the content a content plug or slot is about,
+// which defaults to its name.
+func contentTag(name string, attrs map[string]interface{}) string {
+	if content, ok := attrs["content"].(string); ok && content != "" {
+		return content
+	}
+	return name
+}
+
+// contentPlugTags returns the sorted content tags of the content plugs
+// of the snap.
+func contentPlugTags(info *snap.Info) []string {
+	var tags []string
+	for _, plug := range info.Plugs {
+		if plug.Interface == "content" {
+			tags = append(tags, contentTag(plug.Name, plug.Attrs))
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// contentProviders returns the seed snaps providing each content, by
+// content tag. If some of the providers of a content are marked as
+// preferred, only these are returned as its providers while the others
+// are returned as alternatives, to be seeded after the snaps consuming
+// the content so that those get connected to the preferred providers.
+func contentProviders(seedSnaps []*snap.SeedSnap, infos map[string]*snap.Info) (providers, alternatives map[string][]*snap.SeedSnap) {
+	providers = make(map[string][]*snap.SeedSnap)
+	for _, sn := range seedSnaps {
+		info := infos[sn.Name]
+		if info == nil {
+			continue
+		}
+		for _, slot := range info.Slots {
+			if slot.Interface == "content" {
+				tag := contentTag(slot.Name, slot.Attrs)
+				providers[tag] = append(providers[tag], sn)
+			}
+		}
+	}
+
+	alternatives = make(map[string][]*snap.SeedSnap)
+	for tag, sns := range providers {
+		var preferred, others []*snap.SeedSnap
+		for _, sn := range sns {
+			if sn.Prefer {
+				preferred = append(preferred, sn)
+			} else {
+				others = append(others, sn)
+			}
+		}
+		if len(preferred) != 0 && len(others) != 0 {
+			providers[tag] = preferred
+			alternatives[tag] = others
+		}
+	}
+	return providers, alternatives
+}
+
+// OrderSeedSnaps returns the seed snaps in the order to install them
+// in: the snapd snap, core, and the kernel and gadget of the model,
+// followed by the other snaps, each after its base if it uses one and
+// after the snaps providing content to its content plugs, and
+// otherwise in seed order. infos holds the information about the seed
+// snaps by name, snaps without any are taken to not use a base nor
+// have plugs or slots.
+// Snaps providing content for which other seed snaps are marked as
+// preferred providers go after all the others instead.
+// It is an error for a base to be missing from the seed or for bases
+// to depend on each other in a cycle. Content providers are instead
+// optional and snaps providing content to each other are left in
+// seed order.
+func OrderSeedSnaps(seedSnaps []*snap.SeedSnap, infos map[string]*snap.Info, model *asserts.Model) ([]*snap.SeedSnap, error) {
+	byName := make(map[string]*snap.SeedSnap, len(seedSnaps))
+	for _, sn := range seedSnaps {
+		byName[sn.Name] = sn
+	}
+	providers, alternatives := contentProviders(seedSnaps, infos)
+	alternative := make(map[string]bool)
+	for _, sns := range alternatives {
+		for _, sn := range sns {
+			alternative[sn.Name] = true
+		}
+	}
+
+	order := make([]*snap.SeedSnap, 0, len(seedSnaps))
+	placed := make(map[string]bool, len(seedSnaps))
+	for _, name := range []string{"snapd", "core", model.Kernel(), model.Gadget()} {
+		if sn := byName[name]; sn != nil && !placed[name] {
+			order = append(order, sn)
+			placed[name] = true
+		}
+	}
+
+	visiting := make(map[string]bool)
+	var place func(sn *snap.SeedSnap) error
+	place = func(sn *snap.SeedSnap) error {
+		if placed[sn.Name] {
+			return nil
+		}
+		if visiting[sn.Name] {
+			return fmt.Errorf("cannot order seed snaps: snap %q is part of a cycle of bases", sn.Name)
+		}
+		visiting[sn.Name] = true
+		if info := infos[sn.Name]; info != nil {
+			if info.Base != "" {
+				base := byName[info.Base]
+				if base == nil {
+					return fmt.Errorf("cannot use snap %q: base %q is missing from the seed", sn.Name, info.Base)
+				}
+				if err := place(base); err != nil {
+					return err
+				}
+			}
+			for _, tag := range contentPlugTags(info) {
+				for _, provider := range providers[tag] {
+					if visiting[provider.Name] {
+						continue
+					}
+					if err := place(provider); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		delete(visiting, sn.Name)
+		placed[sn.Name] = true
+		order = append(order, sn)
+		return nil
+	}
+	for _, sn := range seedSnaps {
+		if alternative[sn.Name] {
+			continue
+		}
+		if err := place(sn); err != nil {
 			return nil, err
 		}
-		sideInfo = *si
-		sideInfo.Private = sn.Private
-		sideInfo.Contact = sn.Contact
 	}
+	for _, sn := range seedSnaps {
+		if err := place(sn); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
 
-	return snapstate.InstallPath(st, &sideInfo, path, sn.Channel, flags)
+// UnseededSnaps returns the snaps of the seed, in seed order, that are
+// not installed in the system yet.
+func UnseededSnaps(st *state.State, seed *snap.Seed) ([]*snap.SeedSnap, error) {
+	var unseeded []*snap.SeedSnap
+	for _, sn := range seed.Snaps {
+		var snapst snapstate.SnapState
+		err := snapstate.Get(st, sn.Name, &snapst)
+		if err == state.ErrNoState {
+			unseeded = append(unseeded, sn)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return unseeded, nil
+}
+
+// seedSnapInstalled returns whether the seed revision of sn is
+// already installed, as happens when seeding was interrupted
+// between snaps.
+func seedSnapInstalled(st *state.State, sn *snap.SeedSnap, sideInfos *seedSideInfos) (bool, error) {
+	var snapst snapstate.SnapState
+	err := snapstate.Get(st, sn.Name, &snapst)
+	if err == state.ErrNoState {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if sn.Unasserted {
+		// local revisions are only known once installed
+		return true, nil
+	}
+	sideInfo, err := sideInfos.get(sn)
+	if err != nil {
+		// let installing the snap report the problem
+		return false, nil
+	}
+	return snapst.Current == sideInfo.Revision, nil
+}
+
+// PopulateStateFromSeedOptions holds options for PopulateStateFromSeed.
+type PopulateStateFromSeedOptions struct {
+	// AggregateErrors makes seeding carry on past problems with
+	// individual seed snaps and report all of them together.
+	AggregateErrors bool
+	// SeedDir is the root of the seed to use instead of
+	// dirs.SnapSeedDir.
+	SeedDir string
+	// Reseed allows to populate the state of an already seeded
+	// system, installing only the snaps from the seed that the
+	// model requires and that are not installed yet.
+	Reseed bool
+	// FetchMissingAssertions makes seeding retrieve from the store
+	// the prerequisite assertions missing from the seed, instead of
+	// requiring the seed assertions to be self-contained.
+	FetchMissingAssertions bool
+	// PrepareDevice makes the prepare-device hook of the gadget, if
+	// it has one, run while seeding, once the gadget is installed and
+	// configured, for devices that need it before seeding completes.
+	// Registration then does not run the hook again.
+	PrepareDevice bool
+}
+
+// seedErrors collects the problems found while going through a seed.
+type seedErrors struct {
+	aggregate bool
+	errs      []error
+}
+
+// add records err. Unless errors are being aggregated err is
+// returned back so that the caller can stop right away.
+func (e *seedErrors) add(err error) error {
+	if !e.aggregate {
+		return err
+	}
+	e.errs = append(e.errs, err)
+	return nil
+}
+
+// err returns nil if no problems were recorded, the only one if there
+// is just one, or an error listing all of them otherwise.
+func (e *seedErrors) err(prefix string) error {
+	switch len(e.errs) {
+	case 0:
+		return nil
+	case 1:
+		return e.errs[0]
+	}
+	var buf bytes.Buffer
+	for _, err := range e.errs {
+		fmt.Fprintf(&buf, "\n- %s", err)
+	}
+	return fmt.Errorf("%s:%s", prefix, buf.Bytes())
 }
 
 func populateStateFromSeedImpl(st *state.State) ([]*state.TaskSet, error) {
-	// check that the state is empty
+	return PopulateStateFromSeed(st, nil)
+}
+
+// PopulateStateFromSeed returns the task sets to seed the system from
+// the seed in opts.SeedDir, or dirs.SnapSeedDir if that is not set.
+// By default it stops at the first problem found, with
+// opts.AggregateErrors set it instead reports the problems with all
+// the seed snaps in one error.
+// On error no task sets are returned; the tasks created up to that
+// point are not part of any change and get pruned eventually.
+func PopulateStateFromSeed(st *state.State, opts *PopulateStateFromSeedOptions) ([]*state.TaskSet, error) {
+	if opts == nil {
+		opts = &PopulateStateFromSeedOptions{}
+	}
+	seedDir := opts.SeedDir
+	if seedDir == "" {
+		seedDir = dirs.SnapSeedDir
+	}
+
+	// check that the state is empty, unless reseeding
 	var seeded bool
 	err := st.Get("seeded", &seeded)
 	if err != nil && err != state.ErrNoState {
 		return nil, err
 	}
-	if seeded {
-		return nil, fmt.Errorf("cannot populate state: already seeded")
+	if seeded && !opts.Reseed {
+		return nil, ErrAlreadySeeded
 	}
 
-	markSeeded := st.NewTask("mark-seeded", i18n.G("Mark system seeded"))
+	markSeeded := st.NewTask("mark-seeded", i18n.G("Complete seeding of the system"))
 
 	// ack all initial assertions
-	model, err := importAssertionsFromSeed(st)
+	model, err := importAssertionsFromSeedDir(st, seedDir, opts.FetchMissingAssertions)
 	if err == errNothingToDo {
 		return []*state.TaskSet{state.NewTaskSet(markSeeded)}, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	seedLogf("imported model brand-id=%q model=%q", model.BrandID(), model.Model())
 
-	seedYamlFile := filepath.Join(dirs.SnapSeedDir, "seed.yaml")
+	// talk to the store (proxy) of the model from the start
+	if err := setupModelStore(st, model); err != nil {
+		return nil, err
+	}
+
+	seedYamlFile := filepath.Join(seedDir, "seed.yaml")
 	if release.OnClassic && !osutil.FileExists(seedYamlFile) {
 		// on classic it is ok to not seed any snaps
 		return []*state.TaskSet{state.NewTaskSet(markSeeded)}, nil
@@ -117,69 +716,221 @@ func populateStateFromSeedImpl(st *state.State) ([]*state.TaskSet, error) {
 		seeding[sn.Name] = sn
 	}
 	alreadySeeded := make(map[string]bool, 3)
+	sideInfos := newSeedSideInfos(st, seedDir)
 
-	tsAll := []*state.TaskSet{}
-	configTss := []*state.TaskSet{}
+	serrs := &seedErrors{aggregate: opts.AggregateErrors}
 
-	// if there are snaps to seed, core needs to be seeded too
-	if len(seed.Snaps) != 0 {
-		coreSeed := seeding["core"]
-		if coreSeed == nil {
-			return nil, fmt.Errorf("cannot proceed without seeding core")
+	// check this before doing any work
+	for _, name := range reqSnaps {
+		if seeding[name] == nil {
+			if err := serrs.add(fmt.Errorf("cannot proceed, model requires snap %q not present in seed", name)); err != nil {
+				return nil, err
+			}
 		}
-		ts, err := installSeedSnap(st, coreSeed, snapstate.Flags{SkipConfigure: true})
-		if err != nil {
+	}
+
+	// work out the order to install the snaps in, bases first
+	infos := seedSnapInfos(seedDir, seed.Snaps)
+	if err := checkSeedKernelAndGadget(seed.Snaps, infos, model); err != nil {
+		if err := serrs.add(err); err != nil {
 			return nil, err
 		}
-		tsAll = append(tsAll, ts)
-		alreadySeeded["core"] = true
-		configTss = append(configTss, snapstate.ConfigureSnap(st, "core", snapstate.UseConfigDefaults))
 	}
-
-	last := 0
-	if kernelName := model.Kernel(); kernelName != "" {
-		kernelSeed := seeding[kernelName]
-		if kernelSeed == nil {
-			return nil, fmt.Errorf("cannot find seed information for kernel snap %q", kernelName)
+	order, err := OrderSeedSnaps(seed.Snaps, infos, model)
+	if err != nil {
+		if err := serrs.add(err); err != nil {
+			return nil, err
 		}
-		ts, err := installSeedSnap(st, kernelSeed, snapstate.Flags{SkipConfigure: true})
+		order = seed.Snaps
+	}
+
+	if seeded {
+		// leave alone everything but the newly required snaps
+		unseeded, err := UnseededSnaps(st, seed)
 		if err != nil {
 			return nil, err
 		}
-		ts.WaitAll(tsAll[last])
-		tsAll = append(tsAll, ts)
-		alreadySeeded[kernelName] = true
-		configTs := snapstate.ConfigureSnap(st, kernelName, snapstate.UseConfigDefaults)
-		configTs.WaitAll(configTss[last])
-		configTss = append(configTss, configTs)
-		last++
+		toSeed := make(map[string]bool, len(unseeded))
+		for _, sn := range unseeded {
+			if required[sn.Name] {
+				toSeed[sn.Name] = true
+			}
+		}
+		for _, sn := range seed.Snaps {
+			if !toSeed[sn.Name] {
+				alreadySeeded[sn.Name] = true
+			}
+		}
+	} else {
+		// skip what got installed before seeding was interrupted
+		for _, sn := range seed.Snaps {
+			installed, err := seedSnapInstalled(st, sn, sideInfos)
+			if err != nil {
+				return nil, err
+			}
+			if installed {
+				alreadySeeded[sn.Name] = true
+			}
+		}
 	}
 
-	if gadgetName := model.Gadget(); gadgetName != "" {
-		gadgetSeed := seeding[gadgetName]
-		if gadgetSeed == nil {
-			return nil, fmt.Errorf("cannot find seed information for gadget snap %q", gadgetName)
+	tsAll := []*state.TaskSet{}
+	configTss := []*state.TaskSet{}
+
+	// the task set installing each snap, in order, with its info
+	var snapTss []*state.TaskSet
+	var snapInfos []*snap.Info
+
+	// installSeed returns a nil task set if installing sn failed
+	// but errors are being aggregated; what describes sn in the log
+	installSeed := func(sn *snap.SeedSnap, what string, flags snapstate.Flags) (*state.TaskSet, *snap.Info, error) {
+		alreadySeeded[sn.Name] = true
+		if err := checkSeedSnapGrade(model, sn); err != nil {
+			return nil, nil, serrs.add(err)
+		}
+		if err := checkSeedSnapChannel(model, sn); err != nil {
+			return nil, nil, serrs.add(err)
 		}
-		ts, err := installSeedSnap(st, gadgetSeed, snapstate.Flags{SkipConfigure: true})
+		if err := checkSeedSnapHold(model, sn, infos[sn.Name]); err != nil {
+			return nil, nil, serrs.add(err)
+		}
+		// on problems deriving the side info leave it to
+		// installSeedSnap to report them
+		sideInfo, _ := sideInfos.get(sn)
+		ts, info, err := installSeedSnap(st, seedDir, sn, sideInfo, flags)
 		if err != nil {
-			return nil, err
+			return nil, nil, serrs.add(err)
+		}
+		snapTss = append(snapTss, ts)
+		snapInfos = append(snapInfos, info)
+		seedLogf("queued %s name=%q revision=%s", what, sn.Name, info.Revision)
+		return ts, info, nil
+	}
+	// installEssential installs core, kernel or gadget and sets up
+	// its configuration
+	installEssential := func(name, what string) error {
+		sn := seeding[name]
+		if sn == nil {
+			return serrs.add(fmt.Errorf("cannot find seed information for %s snap %q", what, name))
+		}
+		if alreadySeeded[name] {
+			// installed already but maybe not configured yet
+			configTss = chainTs(configTss, snapstate.ConfigureSnap(st, name, snapstate.UseConfigDefaults))
+			return nil
+		}
+		ts, _, err := installSeed(sn, what+" snap", snapstate.Flags{SkipConfigure: true})
+		if err != nil || ts == nil {
+			return err
+		}
+		tsAll = chainTs(tsAll, ts)
+		configTss = chainTs(configTss, snapstate.ConfigureSnap(st, name, snapstate.UseConfigDefaults))
+		return nil
+	}
+
+	// when reseeding snapd, core, kernel and gadget are already set up
+	if !seeded {
+		// the gadget defaults need to be for snaps being seeded
+		if gadgetSeed := seeding[model.Gadget()]; gadgetSeed != nil {
+			if err := checkGadgetDefaults(st, seedDir, gadgetSeed, seed.Snaps, sideInfos); err != nil {
+				if err := serrs.add(err); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// the snapd snap, if seeded, needs to be set up before anything else
+		if snapdSeed := seeding["snapd"]; snapdSeed != nil && !alreadySeeded["snapd"] {
+			ts, _, err := installSeed(snapdSeed, "snap", snapstate.Flags{SkipConfigure: true})
+			if err != nil {
+				return nil, err
+			}
+			if ts != nil {
+				tsAll = append(tsAll, ts)
+			}
+		}
+
+		// core needs to be seeded too if any of the snaps needs it,
+		// unless it is already installed as can be on classic
+		if seeding["core"] == nil && seedNeedsCore(seed.Snaps, infos, model) {
+			var snapst snapstate.SnapState
+			err := snapstate.Get(st, "core", &snapst)
+			if err != nil && err != state.ErrNoState {
+				return nil, err
+			}
+			if !snapst.IsInstalled() {
+				if err := serrs.add(fmt.Errorf("cannot proceed without seeding core")); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if seeding["core"] != nil {
+			if err := installEssential("core", "core"); err != nil {
+				return nil, err
+			}
+		}
+
+		// kernel and gadget, in the order asked for by the model
+		essentials := []struct{ name, what string }{
+			{model.Kernel(), "kernel"},
+			{model.Gadget(), "gadget"},
+		}
+		if model.GadgetFirst() {
+			essentials[0], essentials[1] = essentials[1], essentials[0]
+		}
+		for _, ess := range essentials {
+			if ess.name == "" {
+				continue
+			}
+			if err := installEssential(ess.name, ess.what); err != nil {
+				return nil, err
+			}
 		}
-		ts.WaitAll(tsAll[last])
-		tsAll = append(tsAll, ts)
-		alreadySeeded[gadgetName] = true
-		configTs := snapstate.ConfigureSnap(st, gadgetName, snapstate.UseConfigDefaults)
-		configTs.WaitAll(configTss[last])
-		configTss = append(configTss, configTs)
-		last++
 	}
 
 	// chain together configuring core, kernel, and gadget after
 	// installing them so that defaults are availabble from gadget
-	configTss[0].WaitAll(tsAll[last])
-	tsAll = append(tsAll, configTss...)
-	last += len(configTss)
+	if len(configTss) != 0 {
+		if len(tsAll) != 0 {
+			configTss[0].WaitAll(tsAll[len(tsAll)-1])
+		}
+		tsAll = append(tsAll, configTss...)
+	}
 
-	for _, sn := range seed.Snaps {
+	// prepare the device once the gadget is set up
+	gadgetInfo := infos[model.Gadget()]
+	if opts.PrepareDevice && !seeded && gadgetInfo != nil && gadgetInfo.Hooks["prepare-device"] != nil {
+		summary := i18n.G("Run prepare-device hook")
+		hooksup := &hookstate.HookSetup{
+			Snap: gadgetInfo.Name(),
+			Hook: "prepare-device",
+		}
+		prepareDevice := hookstate.HookTask(st, summary, hooksup, map[string]interface{}{"seeding": true})
+		ts := state.NewTaskSet(prepareDevice)
+		if len(tsAll) != 0 {
+			ts.WaitAll(tsAll[len(tsAll)-1])
+		}
+		tsAll = append(tsAll, ts)
+		seedLogf("queued prepare-device hook of gadget name=%q", gadgetInfo.Name())
+	}
+	last := len(tsAll) - 1
+
+	// the remaining snaps only need core, kernel and gadget to be
+	// installed and configured, and their base if they have one,
+	// otherwise they do not depend on each other so they can be
+	// installed in parallel
+	baseTss := make(map[string]*state.TaskSet)
+	contentTss := make(map[string][]*state.TaskSet)
+	consumerTss := make(map[string][]*state.TaskSet)
+	_, alternatives := contentProviders(seed.Snaps, infos)
+	isAlternative := func(tag, name string) bool {
+		for _, sn := range alternatives[tag] {
+			if sn.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	for _, sn := range order {
 		if alreadySeeded[sn.Name] {
 			continue
 		}
@@ -189,103 +940,500 @@ func populateStateFromSeedImpl(st *state.State) ([]*state.TaskSet, error) {
 			flags.Required = true
 		}
 
-		ts, err := installSeedSnap(st, sn, flags)
+		ts, info, err := installSeed(sn, "snap", flags)
 		if err != nil {
 			return nil, err
 		}
+		if ts == nil {
+			continue
+		}
 
-		ts.WaitAll(tsAll[last])
+		if last >= 0 {
+			ts.WaitAll(tsAll[last])
+		}
 		tsAll = append(tsAll, ts)
-		last++
+
+		if info.Type == snap.TypeBase {
+			baseTss[info.Name()] = ts
+		}
+		// bases come first in the order
+		if baseTs := baseTss[info.Base]; baseTs != nil {
+			ts.WaitAll(baseTs)
+		}
+		// and so do content providers, so that the content plugs
+		// can be connected to them
+		for _, tag := range contentPlugTags(info) {
+			for _, providerTs := range contentTss[tag] {
+				ts.WaitAll(providerTs)
+			}
+			consumerTss[tag] = append(consumerTss[tag], ts)
+		}
+		for _, slot := range info.Slots {
+			if slot.Interface == "content" {
+				tag := contentTag(slot.Name, slot.Attrs)
+				if isAlternative(tag, info.Name()) {
+					// only once the consumers got connected to
+					// the preferred providers
+					for _, consumerTs := range consumerTss[tag] {
+						ts.WaitAll(consumerTs)
+					}
+					continue
+				}
+				contentTss[tag] = append(contentTss[tag], ts)
+			}
+		}
+	}
+
+	if err := serrs.err("cannot seed"); err != nil {
+		return nil, err
+	}
+
+	// label the first task of each snap so that the progress
+	// through the seed can be followed
+	for i, ts := range snapTss {
+		info := snapInfos[i]
+		var summary string
+		if info.Revision.Unset() {
+			summary = fmt.Sprintf(i18n.G("Seed snap %q (%d of %d)"), info.Name(), i+1, len(snapTss))
+		} else {
+			summary = fmt.Sprintf(i18n.G("Seed snap %q (revision %s, %d of %d)"), info.Name(), info.Revision, i+1, len(snapTss))
+		}
+		ts.Tasks()[0].SetSummary(summary)
 	}
 
 	if len(tsAll) == 0 {
+		if seeded {
+			// nothing new to install
+			seedLogf("appended mark-seeded, nothing new to seed")
+			return []*state.TaskSet{state.NewTaskSet(markSeeded)}, nil
+		}
 		return nil, fmt.Errorf("cannot proceed, no snaps to seed")
 	}
 
-	ts := tsAll[len(tsAll)-1]
-	markSeeded.WaitAll(ts)
+	// seeding is done only once everything is done
+	for _, ts := range tsAll {
+		markSeeded.WaitAll(ts)
+	}
 	tsAll = append(tsAll, state.NewTaskSet(markSeeded))
+	seedLogf("appended mark-seeded task-sets=%d", len(tsAll)-1)
 
 	return tsAll, nil
 }
 
-func readAsserts(fn string, batch *assertstate.Batch) ([]*asserts.Ref, error) {
-	f, err := os.Open(fn)
+// ValidateSeed checks that the seed in seedDir is consistent without
+// touching the system state nor depending on it, so that seeds can be
+// checked where images are built: the seed must have exactly
+// one model assertion, the core, kernel and gadget snaps the model
+// refers to must be in the seed, as the only kernel and gadget, and
+// all asserted snaps must have matching signatures. All the problems
+// found are reported.
+func ValidateSeed(seedDir string) error {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	// use a scratch assertion database
+	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
+		Backstore:       asserts.NewMemoryBackstore(),
+		Trusted:         sysdb.Trusted(),
+		OtherPredefined: sysdb.Generic(),
+	})
+	if err != nil {
+		return err
+	}
+	assertstate.ReplaceDB(st, db)
+
+	serrs := &seedErrors{aggregate: true}
+
+	// without the model most checks cannot be done but the seed.yaml
+	// can still be checked
+	model, _, err := readSeedAssertions(st, seedDir, false)
+	if err != nil {
+		serrs.add(err)
+	}
+
+	seedYamlFile := filepath.Join(seedDir, "seed.yaml")
+	if model != nil && model.Classic() && !osutil.FileExists(seedYamlFile) {
+		// classic seeds can do without snaps
+		return serrs.err("cannot validate seed")
+	}
+	seed, err := snap.ReadSeedYaml(seedYamlFile)
+	if err != nil {
+		serrs.add(err)
+	}
+	if model == nil || seed == nil {
+		return serrs.err("cannot validate seed")
+	}
+
+	seeding := make(map[string]*snap.SeedSnap, len(seed.Snaps))
+	for _, sn := range seed.Snaps {
+		seeding[sn.Name] = sn
+	}
+	infos := seedSnapInfos(seedDir, seed.Snaps)
+	if seeding["core"] == nil && seedNeedsCore(seed.Snaps, infos, model) {
+		serrs.add(fmt.Errorf("cannot proceed without seeding core"))
+	}
+	if err := checkSeedKernelAndGadget(seed.Snaps, infos, model); err != nil {
+		serrs.add(err)
+	}
+	if kernelName := model.Kernel(); kernelName != "" && seeding[kernelName] == nil {
+		serrs.add(fmt.Errorf("cannot find seed information for kernel snap %q", kernelName))
+	}
+	if gadgetName := model.Gadget(); gadgetName != "" && seeding[gadgetName] == nil {
+		serrs.add(fmt.Errorf("cannot find seed information for gadget snap %q", gadgetName))
+	}
+	if _, err := OrderSeedSnaps(seed.Snaps, infos, model); err != nil {
+		serrs.add(err)
+	}
+	for _, sn := range seed.Snaps {
+		if err := checkSeedSnapGrade(model, sn); err != nil {
+			serrs.add(err)
+		}
+		if err := checkSeedSnapChannel(model, sn); err != nil {
+			serrs.add(err)
+		}
+		if err := checkSeedSnapHold(model, sn, infos[sn.Name]); err != nil {
+			serrs.add(err)
+		}
+		if _, err := seedSnapSideInfo(st, seedDir, sn); err != nil {
+			serrs.add(err)
+		}
+	}
+
+	return serrs.err("cannot validate seed")
+}
+
+// SeedFromState describes the snaps installed in st as a seed, the
+// inverse of seeding. It returns the seed to write as seed.yaml, the
+// paths of the snap files to copy into the seed snaps directory and
+// the assertions for the seed assertions directory, prerequisites
+// first. snapd, core, kernel and gadget come first in the seed, the
+// other snaps follow sorted by name.
+func SeedFromState(st *state.State) (seed *snap.Seed, snapFiles []string, assertions []asserts.Assertion, err error) {
+	model, err := Model(st)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	snapStates, err := snapstate.All(st)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	db := assertstate.DB(st)
+	retrieve := func(ref *asserts.Ref) (asserts.Assertion, error) {
+		return ref.Resolve(db.Find)
+	}
+	save := func(a asserts.Assertion) error {
+		assertions = append(assertions, a)
+		return nil
+	}
+	f := asserts.NewFetcher(db, retrieve, save)
+	if err := f.Save(model); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// snapd, core, kernel and gadget first, then the rest by name
+	essentials := []string{"snapd", "core", model.Kernel(), model.Gadget()}
+	names := make([]string, 0, len(snapStates))
+	isEssential := make(map[string]bool, len(essentials))
+	for _, name := range essentials {
+		if snapStates[name] != nil && !isEssential[name] {
+			names = append(names, name)
+			isEssential[name] = true
+		}
+	}
+	others := make([]string, 0, len(snapStates))
+	for name := range snapStates {
+		if !isEssential[name] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+
+	seed = &snap.Seed{}
+	for _, name := range names {
+		snapst := snapStates[name]
+		si := snapst.CurrentSideInfo()
+		if si == nil {
+			continue
+		}
+		snapFile := snap.MountFile(name, si.Revision)
+		seed.Snaps = append(seed.Snaps, &snap.SeedSnap{
+			Name:       name,
+			SnapID:     si.SnapID,
+			Channel:    snapst.Channel,
+			Cohort:     snapst.CohortKey,
+			DevMode:    snapst.DevMode,
+			Classic:    snapst.Classic,
+			Private:    si.Private,
+			Contact:    si.Contact,
+			Unasserted: si.SnapID == "",
+			File:       filepath.Base(snapFile),
+		})
+		snapFiles = append(snapFiles, snapFile)
+
+		if si.SnapID == "" {
+			continue
+		}
+		revs, err := db.FindMany(asserts.SnapRevisionType, map[string]string{
+			"snap-id":       si.SnapID,
+			"snap-revision": si.Revision.String(),
+		})
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("cannot find signatures with metadata for snap %q: %v", name, err)
+		}
+		for _, rev := range revs {
+			if err := f.Save(rev); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+
+	return seed, snapFiles, assertions, nil
+}
+
+// chainTs makes ts wait for the last task set in tss, if any, and
+// appends it to tss.
+func chainTs(tss []*state.TaskSet, ts *state.TaskSet) []*state.TaskSet {
+	if len(tss) != 0 {
+		ts.WaitAll(tss[len(tss)-1])
+	}
+	return append(tss, ts)
+}
+
+// readAsserts adds the assertions in the file fn to the batch and
+// returns them.
+func readAsserts(fn string, batch *assertstate.Batch) ([]asserts.Assertion, error) {
+	data, err := ioutil.ReadFile(fn)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
-	return batch.AddStream(f)
+	// tolerate files left empty by image tooling
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var as []asserts.Assertion
+	dec := asserts.NewDecoder(bytes.NewReader(data))
+	for {
+		a, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := batch.Add(a); err != nil {
+			return nil, err
+		}
+		as = append(as, a)
+	}
+	return as, nil
+}
+
+// hasDeviceKeys returns whether any device key was provisioned, the
+// device directory only holds the device keys.
+func hasDeviceKeys() bool {
+	found := false
+	filepath.Walk(dirs.SnapDeviceDir, func(path string, fi os.FileInfo, err error) error {
+		if err == nil && fi.Mode().IsRegular() {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// checkSeedSerialProvisioned returns whether the device key of the
+// serial assertion from the seed was provisioned together with it.
+// Without any device keys, as when seeding a system built from an
+// image without them, the serial cannot be used yet but that is not an
+// error.
+func checkSeedSerialProvisioned(serial *asserts.Serial) (bool, error) {
+	if !hasDeviceKeys() {
+		return false, nil
+	}
+	keypairMgr, err := asserts.OpenFSKeypairManager(dirs.SnapDeviceDir)
+	if err != nil {
+		return false, err
+	}
+	if _, err := keypairMgr.Get(serial.DeviceKey().ID()); err != nil {
+		return false, fmt.Errorf("cannot seed with serial assertion %q without its device key: %v", serial.Serial(), err)
+	}
+	return true, nil
 }
 
 func importAssertionsFromSeed(st *state.State) (*asserts.Model, error) {
+	return importAssertionsFromSeedDir(st, dirs.SnapSeedDir, false)
+}
+
+// importAssertionsFromSeedDir adds the assertions from the seed in
+// seedDir to the system assertion database and returns the model,
+// checking that the seed is for this system. With fetchMissing the
+// prerequisites missing from the seed are retrieved from the store. A
+// serial assertion in the seed, for a device key already provisioned,
+// sets the serial of the device.
+func importAssertionsFromSeedDir(st *state.State, seedDir string, fetchMissing bool) (*asserts.Model, error) {
 	device, err := auth.Device(st)
 	if err != nil {
 		return nil, err
 	}
 
-	// set device,model from the model assertion
-	assertSeedDir := filepath.Join(dirs.SnapSeedDir, "assertions")
-	dc, err := ioutil.ReadDir(assertSeedDir)
+	_, err = ioutil.ReadDir(filepath.Join(seedDir, "assertions"))
 	if release.OnClassic && os.IsNotExist(err) {
 		// on classic seeding is optional
 		return nil, errNothingToDo
 	}
+
+	modelAssertion, serial, err := readSeedAssertions(st, seedDir, fetchMissing)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read assert seed dir: %s", err)
+		return nil, err
+	}
+
+	classicModel := modelAssertion.Classic()
+	if release.OnClassic != classicModel {
+		var msg string
+		if classicModel {
+			msg = "cannot seed an all-snaps system with a classic model"
+		} else {
+			msg = "cannot seed a classic system with an all-snaps model"
+		}
+		return nil, fmt.Errorf(msg)
+	}
+
+	// set device,model from the model assertion
+	device.Brand = modelAssertion.BrandID()
+	device.Model = modelAssertion.Model()
+
+	// a serial assertion in the seed provisions the device serial,
+	// with no need to register with the store
+	if serial != nil {
+		provisioned, err := checkSeedSerialProvisioned(serial)
+		if err != nil {
+			return nil, err
+		}
+		if provisioned {
+			device.KeyID = serial.DeviceKey().ID()
+			device.Serial = serial.Serial()
+		}
+	}
+
+	if err := auth.SetDevice(st, device); err != nil {
+		return nil, err
+	}
+
+	return modelAssertion, nil
+}
+
+// readSeedAssertions adds the assertions from the seed in seedDir to
+// the assertion database of st and returns the model together with
+// the serial assertion, if any, checking that the assertions are
+// consistent with each other. It doesn't look at the system the seed
+// is on, so it can be used to check a seed when building an image.
+func readSeedAssertions(st *state.State, seedDir string, fetchMissing bool) (*asserts.Model, *asserts.Serial, error) {
+	// assertions can be organized in subdirectories
+	assertSeedDir := filepath.Join(seedDir, "assertions")
+	var fns []string
+	err := filepath.Walk(assertSeedDir, func(fn string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			fns = append(fns, fn)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read assert seed dir: %s", err)
 	}
 
 	// collect
-	var modelRef *asserts.Ref
+	var modelRef, storeRef, serialRef *asserts.Ref
+	var modelEncoded []byte
+	var added []string
+	seen := make(map[string]bool)
 	batch := assertstate.NewBatch()
-	for _, fi := range dc {
-		fn := filepath.Join(assertSeedDir, fi.Name())
-		refs, err := readAsserts(fn, batch)
+	for _, fn := range fns {
+		as, err := readAsserts(fn, batch)
+		if ufe, ok := err.(*asserts.UnsupportedFormatError); ok {
+			// the seed was built for a newer snapd
+			return nil, nil, fmt.Errorf("seed requires a newer snapd (assertion %q uses format %d)", ufe.Ref.Type.Name, ufe.Format)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("cannot read assertions: %s", err)
+			return nil, nil, fmt.Errorf("cannot read assertions: %s", err)
 		}
-		for _, ref := range refs {
+		for _, a := range as {
+			ref := a.Ref()
+			if !seen[ref.Unique()] {
+				seen[ref.Unique()] = true
+				added = append(added, ref.Unique())
+			}
 			if ref.Type == asserts.ModelType {
-				if modelRef != nil && modelRef.Unique() != ref.Unique() {
-					return nil, fmt.Errorf("cannot add more than one model assertion")
+				// image tooling can leave identical copies of
+				// the model around, any other model is a conflict
+				encoded := asserts.Encode(a)
+				if modelRef != nil && !bytes.Equal(modelEncoded, encoded) {
+					return nil, nil, fmt.Errorf("cannot add more than one model assertion")
 				}
 				modelRef = ref
+				modelEncoded = encoded
+			}
+			if ref.Type == asserts.StoreType {
+				if storeRef != nil && storeRef.Unique() != ref.Unique() {
+					return nil, nil, fmt.Errorf("cannot add more than one store assertion")
+				}
+				storeRef = ref
+			}
+			if ref.Type == asserts.SerialType {
+				if serialRef != nil && serialRef.Unique() != ref.Unique() {
+					return nil, nil, fmt.Errorf("cannot add more than one serial assertion")
+				}
+				serialRef = ref
 			}
 		}
 	}
 	// verify we have one model assertion
 	if modelRef == nil {
-		return nil, fmt.Errorf("need a model assertion")
+		return nil, nil, fmt.Errorf("need a model assertion")
 	}
 
-	if err := batch.Commit(st); err != nil {
-		return nil, err
+	if fetchMissing {
+		err = batch.CommitFetchingMissing(st, 0)
+	} else {
+		err = batch.Commit(st)
+	}
+	if err != nil {
+		return nil, nil, err
 	}
+	// keep track of what came from the seed, for debugging
+	st.Set("seed-assertions", added)
 
 	a, err := modelRef.Resolve(assertstate.DB(st).Find)
 	if err != nil {
-		return nil, fmt.Errorf("internal error: cannot find just added assertion %v: %v", modelRef, err)
+		return nil, nil, fmt.Errorf("internal error: cannot find just added assertion %v: %v", modelRef, err)
 	}
 	modelAssertion := a.(*asserts.Model)
 
-	classicModel := modelAssertion.Classic()
-	if release.OnClassic != classicModel {
-		var msg string
-		if classicModel {
-			msg = "cannot seed an all-snaps system with a classic model"
-		} else {
-			msg = "cannot seed a classic system with an all-snaps model"
-		}
-		return nil, fmt.Errorf(msg)
+	// a store assertion in the seed is for the store of the model
+	if storeRef != nil && modelAssertion.Store() == "" {
+		return nil, nil, fmt.Errorf("cannot seed with store assertion for %q, the model does not use a store", storeRef.PrimaryKey[0])
+	}
+	if storeRef != nil && storeRef.PrimaryKey[0] != modelAssertion.Store() {
+		return nil, nil, fmt.Errorf("cannot seed with store assertion for %q, the model uses store %q", storeRef.PrimaryKey[0], modelAssertion.Store())
 	}
 
-	// set device,model from the model assertion
-	device.Brand = modelAssertion.BrandID()
-	device.Model = modelAssertion.Model()
-	if err := auth.SetDevice(st, device); err != nil {
-		return nil, err
+	// a serial assertion in the seed is for the model
+	var serial *asserts.Serial
+	if serialRef != nil {
+		a, err := serialRef.Resolve(assertstate.DB(st).Find)
+		if err != nil {
+			return nil, nil, fmt.Errorf("internal error: cannot find just added assertion %v: %v", serialRef, err)
+		}
+		serial = a.(*asserts.Serial)
+		if serial.BrandID() != modelAssertion.BrandID() || serial.Model() != modelAssertion.Model() {
+			return nil, nil, fmt.Errorf("cannot seed with serial assertion for model %s/%s, the model is %s/%s", serial.BrandID(), serial.Model(), modelAssertion.BrandID(), modelAssertion.Model())
+		}
 	}
 
-	return modelAssertion, nil
+	return modelAssertion, serial, nil
 }
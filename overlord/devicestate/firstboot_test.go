@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -36,16 +37,19 @@ import (
 	"github.com/snapcore/snapd/asserts/sysdb"
 	"github.com/snapcore/snapd/boot/boottest"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/devicestate/devicestatetest"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/storestate"
 	"github.com/snapcore/snapd/partition"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
@@ -187,6 +191,7 @@ func (s *FirstBootTestSuite) TestPopulateFromSeedErrorsOnState(c *C) {
 
 	_, err := devicestate.PopulateStateFromSeedImpl(st)
 	c.Assert(err, ErrorMatches, "cannot populate state: already seeded")
+	c.Check(err, Equals, devicestate.ErrAlreadySeeded)
 }
 
 func (s *FirstBootTestSuite) makeAssertedSnap(c *C, snapYaml string, files [][]string, revision snap.Revision, developerID string) (snapFname string, snapDecl *asserts.SnapDeclaration, snapRev *asserts.SnapRevision) {
@@ -238,7 +243,7 @@ version: 1.0
 type: os`
 	coreFname, coreDecl, coreRev := s.makeAssertedSnap(c, snapYaml, files, snap.R(1), "canonical")
 
-	writeAssertionsToFile("core.asserts", []asserts.Assertion{coreRev, coreDecl})
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "core.asserts", []asserts.Assertion{coreRev, coreDecl})
 
 	// put kernel snap into the SnapBlobDir
 	snapYaml = `name: pc-kernel
@@ -246,7 +251,7 @@ version: 1.0
 type: kernel`
 	kernelFname, kernelDecl, kernelRev := s.makeAssertedSnap(c, snapYaml, files, snap.R(1), "canonical")
 
-	writeAssertionsToFile("kernel.asserts", []asserts.Assertion{kernelRev, kernelDecl})
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "kernel.asserts", []asserts.Assertion{kernelRev, kernelDecl})
 
 	gadgetYaml := `
 volumes:
@@ -275,7 +280,7 @@ version: 1.0
 type: gadget`
 	gadgetFname, gadgetDecl, gadgetRev := s.makeAssertedSnap(c, snapYaml, files, snap.R(1), "canonical")
 
-	writeAssertionsToFile("gadget.asserts", []asserts.Assertion{gadgetRev, gadgetDecl})
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "gadget.asserts", []asserts.Assertion{gadgetRev, gadgetDecl})
 
 	return coreFname, kernelFname, gadgetFname
 }
@@ -479,22 +484,6 @@ func (s *FirstBootTestSuite) TestPopulateFromSeedMissingBootloader(c *C) {
 	c.Assert(chg.Err(), ErrorMatches, `(?s).* cannot determine bootloader.*`)
 }
 
-func writeAssertionsToFile(fn string, assertions []asserts.Assertion) {
-	multifn := filepath.Join(dirs.SnapSeedDir, "assertions", fn)
-	f, err := os.Create(multifn)
-	if err != nil {
-		panic(err)
-	}
-	defer f.Close()
-	enc := asserts.NewEncoder(f)
-	for _, a := range assertions {
-		err := enc.Encode(a)
-		if err != nil {
-			panic(err)
-		}
-	}
-}
-
 func (s *FirstBootTestSuite) TestPopulateFromSeedHappyMultiAssertsFiles(c *C) {
 	bootloader := boottest.NewMockBootloader("mock", c.MkDir())
 	partition.ForceBootloader(bootloader)
@@ -515,18 +504,18 @@ func (s *FirstBootTestSuite) TestPopulateFromSeedHappyMultiAssertsFiles(c *C) {
 version: 1.0`
 	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, snapYaml, nil, snap.R(128), "developerid")
 
-	writeAssertionsToFile("foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
 
 	// put a 2nd firstboot snap into the SnapBlobDir
 	snapYaml = `name: bar
 version: 1.0`
 	barFname, barDecl, barRev := s.makeAssertedSnap(c, snapYaml, nil, snap.R(65), "developerid")
 
-	writeAssertionsToFile("bar.asserts", []asserts.Assertion{devAcct, barDecl, barRev})
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "bar.asserts", []asserts.Assertion{devAcct, barDecl, barRev})
 
 	// add a model assertion and its chain
 	assertsChain := s.makeModelAssertionChain(c, "my-model")
-	writeAssertionsToFile("model.asserts", assertsChain)
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
 
 	// create a seed.yaml
 	content := []byte(fmt.Sprintf(`
@@ -563,43 +552,2352 @@ snaps:
 	chg1 := st.NewChange("become-operational", "init device")
 	chg1.SetStatus(state.DoingStatus)
 
-	st.Unlock()
-	err = s.overlord.Settle(settleTimeout)
+	st.Unlock()
+	err = s.overlord.Settle(settleTimeout)
+	st.Lock()
+	c.Assert(chg.Err(), IsNil)
+	c.Assert(err, IsNil)
+
+	// and check the snap got correctly installed
+	c.Check(osutil.FileExists(filepath.Join(dirs.SnapMountDir, "foo", "128", "meta", "snap.yaml")), Equals, true)
+
+	// and check the snap got correctly installed
+	c.Check(osutil.FileExists(filepath.Join(dirs.SnapMountDir, "bar", "65", "meta", "snap.yaml")), Equals, true)
+
+	// verify
+	r, err := os.Open(dirs.SnapStateFile)
+	c.Assert(err, IsNil)
+	state, err := state.ReadState(nil, r)
+	c.Assert(err, IsNil)
+
+	state.Lock()
+	defer state.Unlock()
+	// check foo
+	info, err := snapstate.CurrentInfo(state, "foo")
+	c.Assert(err, IsNil)
+	c.Check(info.SnapID, Equals, "foo-snap-id")
+	c.Check(info.Revision, Equals, snap.R(128))
+	pubAcct, err := assertstate.Publisher(st, info.SnapID)
+	c.Assert(err, IsNil)
+	c.Check(pubAcct.AccountID(), Equals, "developerid")
+
+	// check bar
+	info, err = snapstate.CurrentInfo(state, "bar")
+	c.Assert(err, IsNil)
+	c.Check(info.SnapID, Equals, "bar-snap-id")
+	c.Check(info.Revision, Equals, snap.R(65))
+	pubAcct, err = assertstate.Publisher(st, info.SnapID)
+	c.Assert(err, IsNil)
+	c.Check(pubAcct.AccountID(), Equals, "developerid")
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedAppSnapsInParallel(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+
+	snapYaml := `name: foo
+version: 1.0`
+	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, snapYaml, nil, snap.R(128), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+
+	snapYaml = `name: bar
+version: 1.0`
+	barFname, barDecl, barRev := s.makeAssertedSnap(c, snapYaml, nil, snap.R(65), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "bar.asserts", []asserts.Assertion{barDecl, barRev})
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+ - name: bar
+   file: %s
+`, coreFname, kernelFname, gadgetFname, fooFname, barFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+	// core, kernel, gadget, their configure task sets, foo, bar
+	// and mark-seeded
+	c.Assert(tsAll, HasLen, 9)
+
+	lastConfigure := tsAll[5].Tasks()
+	fooTasks := tsAll[6].Tasks()
+	barTasks := tsAll[7].Tasks()
+
+	// both apps wait for core, kernel and gadget to be configured
+	c.Check(fooTasks[0].WaitTasks(), testutil.Contains, lastConfigure[len(lastConfigure)-1])
+	c.Check(barTasks[0].WaitTasks(), testutil.Contains, lastConfigure[len(lastConfigure)-1])
+
+	// but not for each other
+	for _, t := range fooTasks {
+		for _, wt := range t.WaitTasks() {
+			c.Check(barTasks, Not(testutil.Contains), wt)
+		}
+	}
+	for _, t := range barTasks {
+		for _, wt := range t.WaitTasks() {
+			c.Check(fooTasks, Not(testutil.Contains), wt)
+		}
+	}
+
+	// the progress through the seed is visible in the summaries
+	c.Check(tsAll[0].Tasks()[0].Summary(), Equals, `Seed snap "core" (revision 1, 1 of 5)`)
+	c.Check(tsAll[2].Tasks()[0].Summary(), Equals, `Seed snap "pc" (revision 1, 3 of 5)`)
+	c.Check(fooTasks[0].Summary(), Equals, `Seed snap "foo" (revision 128, 4 of 5)`)
+	c.Check(barTasks[0].Summary(), Equals, `Seed snap "bar" (revision 65, 5 of 5)`)
+
+	// mark-seeded waits for all of them
+	markSeeded := tsAll[8].Tasks()[0]
+	c.Check(markSeeded.Kind(), Equals, "mark-seeded")
+	c.Check(markSeeded.Summary(), Equals, "Complete seeding of the system")
+	c.Check(markSeeded.WaitTasks(), testutil.Contains, fooTasks[len(fooTasks)-1])
+	c.Check(markSeeded.WaitTasks(), testutil.Contains, barTasks[len(barTasks)-1])
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedWithBases(c *C) {
+	bootloader := boottest.NewMockBootloader("mock", c.MkDir())
+	partition.ForceBootloader(bootloader)
+	defer partition.ForceBootloader(nil)
+	bootloader.SetBootVars(map[string]string{
+		"snap_core":   "core_1.snap",
+		"snap_kernel": "pc-kernel_1.snap",
+	})
+
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+
+	snapYaml := `name: core18
+version: 1.0
+type: base`
+	core18Fname, core18Decl, core18Rev := s.makeAssertedSnap(c, snapYaml, nil, snap.R(2), "canonical")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "core18.asserts", []asserts.Assertion{core18Rev, core18Decl})
+
+	snapYaml = `name: foo
+version: 1.0
+base: core18`
+	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, snapYaml, nil, snap.R(128), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	// foo is listed before its base on purpose
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+ - name: core18
+   file: %s
+`, coreFname, kernelFname, gadgetFname, fooFname, core18Fname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+	c.Assert(tsAll, HasLen, 9)
+
+	// foo is set up after its base and waits for it
+	core18Tasks := tsAll[6].Tasks()
+	fooTasks := tsAll[7].Tasks()
+	c.Check(fooTasks[0].WaitTasks(), testutil.Contains, core18Tasks[len(core18Tasks)-1])
+
+	chg := st.NewChange("seed", "run the populate from seed changes")
+	for _, ts := range tsAll {
+		chg.AddAll(ts)
+	}
+	c.Assert(st.Changes(), HasLen, 1)
+
+	// avoid device reg
+	chg1 := st.NewChange("become-operational", "init device")
+	chg1.SetStatus(state.DoingStatus)
+
+	st.Unlock()
+	err = s.overlord.Settle(settleTimeout)
+	st.Lock()
+	c.Assert(chg.Err(), IsNil)
+	c.Assert(err, IsNil)
+
+	c.Check(osutil.FileExists(filepath.Join(dirs.SnapMountDir, "core18", "2", "meta", "snap.yaml")), Equals, true)
+	c.Check(osutil.FileExists(filepath.Join(dirs.SnapMountDir, "foo", "128", "meta", "snap.yaml")), Equals, true)
+
+	info, err := snapstate.CurrentInfo(st, "core18")
+	c.Assert(err, IsNil)
+	c.Check(info.Type, Equals, snap.TypeBase)
+	info, err = snapstate.CurrentInfo(st, "foo")
+	c.Assert(err, IsNil)
+	c.Check(info.Base, Equals, "core18")
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedAutoConnects(c *C) {
+	bootloader := boottest.NewMockBootloader("mock", c.MkDir())
+	partition.ForceBootloader(bootloader)
+	defer partition.ForceBootloader(nil)
+	bootloader.SetBootVars(map[string]string{
+		"snap_core":   "core_1.snap",
+		"snap_kernel": "pc-kernel_1.snap",
+	})
+
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+	snapYaml := `name: foo
+version: 1.0
+apps:
+ foo:
+  command: bin/foo
+  plugs: [network]`
+	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, snapYaml, nil, snap.R(128), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+`, coreFname, kernelFname, gadgetFname, fooFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+
+	// auto-connection happens as part of setting up the security
+	// profiles of each seeded snap, before the state is marked seeded
+	markSeeded := tsAll[len(tsAll)-1].Tasks()[0]
+	c.Assert(markSeeded.Kind(), Equals, "mark-seeded")
+	for _, ts := range tsAll[:len(tsAll)-1] {
+		var setupProfiles *state.Task
+		for _, t := range ts.Tasks() {
+			if t.Kind() == "setup-profiles" {
+				setupProfiles = t
+				break
+			}
+		}
+		if setupProfiles == nil {
+			// configure task sets
+			continue
+		}
+		tasks := ts.Tasks()
+		c.Check(markSeeded.WaitTasks(), testutil.Contains, tasks[len(tasks)-1])
+	}
+
+	chg := st.NewChange("seed", "run the populate from seed changes")
+	for _, ts := range tsAll {
+		chg.AddAll(ts)
+	}
+
+	// avoid device reg
+	chg1 := st.NewChange("become-operational", "init device")
+	chg1.SetStatus(state.DoingStatus)
+
+	st.Unlock()
+	err = s.overlord.Settle(settleTimeout)
+	st.Lock()
+	c.Assert(chg.Err(), IsNil)
+	c.Assert(err, IsNil)
+
+	var conns map[string]interface{}
+	err = st.Get("conns", &conns)
+	c.Assert(err, IsNil)
+	c.Check(conns, DeepEquals, map[string]interface{}{
+		"foo:network core:network": map[string]interface{}{
+			"interface": "network", "auto": true,
+		},
+	})
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedOnlyUnassertedAppSnaps(c *C) {
+	release.OnClassic = true
+
+	mockSnapFile := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 1.0", nil)
+	fooFname := filepath.Base(mockSnapFile)
+	err := os.Rename(mockSnapFile, filepath.Join(dirs.SnapSeedDir, "snaps", fooFname))
+	c.Assert(err, IsNil)
+
+	// no core, kernel nor gadget to configure
+	assertsChain := s.makeModelAssertionChain(c, "my-model-classic-no-gadget")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: foo
+   file: %s
+   unasserted: true
+`, fooFname))
+	err = ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// no panic but a clean error
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, "cannot proceed without seeding core")
+
+	// also when going past the problem to aggregate errors
+	_, err = devicestate.PopulateStateFromSeed(st, &devicestate.PopulateStateFromSeedOptions{
+		AggregateErrors: true,
+	})
+	c.Assert(err, ErrorMatches, "cannot proceed without seeding core")
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedOnClassicPreinstalledCore(c *C) {
+	release.OnClassic = true
+
+	seedDir := devicestatetest.MakeSeed(c, []*snap.SeedSnap{
+		{Name: "foo", Unasserted: true},
+	}, map[string]string{
+		"foo": snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 1.0", nil),
+	}, s.makeModelAssertionChain(c, "my-model-classic-no-gadget"))
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// core came preinstalled
+	snapstate.Set(st, "core", &snapstate.SnapState{
+		Active:   true,
+		Sequence: []*snap.SideInfo{{RealName: "core", Revision: snap.R(1)}},
+		Current:  snap.R(1),
+	})
+
+	tsAll, err := devicestate.PopulateStateFromSeed(st, &devicestate.PopulateStateFromSeedOptions{SeedDir: seedDir})
+	c.Assert(err, IsNil)
+	snapsup, err := snapstate.TaskSnapSetup(tsAll[0].Tasks()[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.Name(), Equals, "foo")
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedOnClassicCoreNeededOnlyAsBase(c *C) {
+	release.OnClassic = true
+
+	core18Fname := devicestatetest.MakeSeedSnap(c, dirs.SnapSeedDir, "name: core18\nversion: 1.0\ntype: base")
+	barFname := devicestatetest.MakeSeedSnap(c, dirs.SnapSeedDir, "name: bar\nversion: 1.0\nbase: core18")
+	fooFname := devicestatetest.MakeSeedSnap(c, dirs.SnapSeedDir, "name: foo\nversion: 1.0")
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model-classic-no-gadget")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	seedYaml := fmt.Sprintf(`
+snaps:
+ - name: core18
+   file: %s
+   unasserted: true
+ - name: bar
+   file: %s
+   unasserted: true
+`, core18Fname, barFname)
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), []byte(seedYaml), 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// no snap needs core
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+
+	// foo does, as it uses no base of its own
+	seedYaml += fmt.Sprintf(` - name: foo
+   file: %s
+   unasserted: true
+`, fooFname)
+	err = ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), []byte(seedYaml), 0644)
+	c.Assert(err, IsNil)
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, "cannot proceed without seeding core")
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedTryMode(c *C) {
+	release.OnClassic = true
+
+	core18Fname := devicestatetest.MakeSeedSnap(c, dirs.SnapSeedDir, "name: core18\nversion: 1.0\ntype: base")
+	// an unpacked snap directory
+	barDir := filepath.Join(dirs.SnapSeedDir, "snaps", "bar")
+	err := os.MkdirAll(filepath.Join(barDir, "meta"), 0755)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(filepath.Join(barDir, "meta", "snap.yaml"), []byte("name: bar\nversion: 1.0\nbase: core18"), 0644)
+	c.Assert(err, IsNil)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model-classic-no-gadget")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	seedYaml := fmt.Sprintf(`
+snaps:
+ - name: core18
+   file: %s
+   unasserted: true
+ - name: bar
+   file: bar
+   unasserted: true
+   try: true
+`, core18Fname)
+	err = ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), []byte(seedYaml), 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+
+	var trySnapsup *snapstate.SnapSetup
+	for _, ts := range tsAll {
+		snapsup, err := snapstate.TaskSnapSetup(ts.Tasks()[0])
+		if err != nil {
+			continue
+		}
+		if snapsup.Name() == "bar" {
+			trySnapsup = snapsup
+		} else {
+			c.Check(snapsup.TryMode, Equals, false)
+		}
+	}
+	c.Assert(trySnapsup, NotNil)
+	c.Check(trySnapsup.TryMode, Equals, true)
+	c.Check(trySnapsup.SnapPath, Equals, barDir)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedTryModeNotDirectory(c *C) {
+	release.OnClassic = true
+
+	barFname := devicestatetest.MakeSeedSnap(c, dirs.SnapSeedDir, "name: bar\nversion: 1.0\nbase: core18")
+	core18Fname := devicestatetest.MakeSeedSnap(c, dirs.SnapSeedDir, "name: core18\nversion: 1.0\ntype: base")
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model-classic-no-gadget")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	seedYaml := fmt.Sprintf(`
+snaps:
+ - name: core18
+   file: %s
+   unasserted: true
+ - name: bar
+   file: %s
+   unasserted: true
+   try: true
+`, core18Fname, barFname)
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), []byte(seedYaml), 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, `cannot seed snap "bar" in try mode: ".*/bar_1.0_all.snap" is not a directory`)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedOnClassicWithSnaps(c *C) {
+	release.OnClassic = true
+
+	coreFname, _, _ := s.makeCoreSnaps(c, false)
+
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, "name: foo\nversion: 1.0", nil, snap.R(128), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+	barFname, barDecl, barRev := s.makeAssertedSnap(c, "name: bar\nversion: 1.0", nil, snap.R(65), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "bar.asserts", []asserts.Assertion{barDecl, barRev})
+
+	// the model has neither kernel nor gadget
+	assertsChain := s.makeModelAssertionChain(c, "my-model-classic-no-gadget")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: foo
+   file: %s
+ - name: bar
+   file: %s
+`, coreFname, fooFname, barFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+	// core, its configure task set, foo, bar and mark-seeded
+	c.Assert(tsAll, HasLen, 5)
+
+	// the apps wait for core to be configured
+	coreConfigure := tsAll[1].Tasks()
+	c.Check(tsAll[2].Tasks()[0].WaitTasks(), testutil.Contains, coreConfigure[len(coreConfigure)-1])
+	c.Check(tsAll[3].Tasks()[0].WaitTasks(), testutil.Contains, coreConfigure[len(coreConfigure)-1])
+
+	// and mark-seeded waits for everything
+	markSeeded := tsAll[4].Tasks()[0]
+	c.Check(markSeeded.Kind(), Equals, "mark-seeded")
+	for _, ts := range tsAll[:4] {
+		tasks := ts.Tasks()
+		c.Check(markSeeded.WaitTasks(), testutil.Contains, tasks[len(tasks)-1])
+	}
+}
+
+func (s *FirstBootTestSuite) makeSnapdSnap(c *C) (snapdFname string) {
+	snapYaml := `name: snapd
+version: 1.0`
+	snapdFname, snapdDecl, snapdRev := s.makeAssertedSnap(c, snapYaml, nil, snap.R(3), "canonical")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "snapd.asserts", []asserts.Assertion{snapdRev, snapdDecl})
+	return snapdFname
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedWithSnapdSnap(c *C) {
+	bootloader := boottest.NewMockBootloader("mock", c.MkDir())
+	partition.ForceBootloader(bootloader)
+	defer partition.ForceBootloader(nil)
+	bootloader.SetBootVars(map[string]string{
+		"snap_core":   "core_1.snap",
+		"snap_kernel": "pc-kernel_1.snap",
+	})
+
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+	snapdFname := s.makeSnapdSnap(c)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: snapd
+   file: %s
+`, coreFname, kernelFname, gadgetFname, snapdFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+	// snapd, core, kernel, gadget, their configure task sets (no
+	// configure for snapd) and mark-seeded
+	c.Assert(tsAll, HasLen, 8)
+
+	// snapd is installed first and core waits for it
+	snapdTasks := tsAll[0].Tasks()
+	snapsup, err := snapstate.TaskSnapSetup(snapdTasks[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.Name(), Equals, "snapd")
+	coreTasks := tsAll[1].Tasks()
+	c.Check(coreTasks[0].WaitTasks(), testutil.Contains, snapdTasks[len(snapdTasks)-1])
+
+	chg := st.NewChange("seed", "run the populate from seed changes")
+	for _, ts := range tsAll {
+		chg.AddAll(ts)
+	}
+	c.Assert(st.Changes(), HasLen, 1)
+
+	// avoid device reg
+	chg1 := st.NewChange("become-operational", "init device")
+	chg1.SetStatus(state.DoingStatus)
+
+	st.Unlock()
+	err = s.overlord.Settle(settleTimeout)
+	st.Lock()
+	c.Assert(chg.Err(), IsNil)
+	c.Assert(err, IsNil)
+
+	_, err = snapstate.CurrentInfo(st, "snapd")
+	c.Check(err, IsNil)
+	_, err = snapstate.CurrentInfo(st, "core")
+	c.Check(err, IsNil)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedWithSnapdSnapNoCore(c *C) {
+	_, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+	snapdFname := s.makeSnapdSnap(c)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: snapd
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, snapdFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+	// snapd, kernel, gadget, their configure task sets and
+	// mark-seeded
+	c.Assert(tsAll, HasLen, 6)
+
+	// the kernel waits for snapd
+	snapdTasks := tsAll[0].Tasks()
+	kernelTasks := tsAll[1].Tasks()
+	c.Check(kernelTasks[0].WaitTasks(), testutil.Contains, snapdTasks[len(snapdTasks)-1])
+	snapsup, err := snapstate.TaskSnapSetup(kernelTasks[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.Name(), Equals, "pc-kernel")
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedDigestMismatch(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+
+	snapYaml := `name: foo
+version: 1.0`
+	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, snapYaml, nil, snap.R(128), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+
+	// corrupt the snap file after its assertions were made
+	f, err := os.OpenFile(filepath.Join(dirs.SnapSeedDir, "snaps", fooFname), os.O_WRONLY|os.O_APPEND, 0644)
+	c.Assert(err, IsNil)
+	_, err = f.Write([]byte("garbage"))
+	c.Assert(err, IsNil)
+	f.Close()
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+`, coreFname, kernelFname, gadgetFname, fooFname))
+	err = ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, `cannot seed snap "foo": file digest does not match assertion`)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedMissingSnapFile(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: foo_1.0_all.snap
+   unasserted: true
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, `cannot seed snap "foo": file ".*/snaps/foo_1.0_all.snap" not found`)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedErrorNoTaskSetsLeak(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, "name: foo\nversion: 1.0", nil, snap.R(128), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+	barFname, barDecl, barRev := s.makeAssertedSnap(c, "name: bar\nversion: 1.0", nil, snap.R(65), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "bar.asserts", []asserts.Assertion{barDecl, barRev})
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	// the snap file for the second app is missing
+	c.Assert(os.Remove(filepath.Join(dirs.SnapSeedDir, "snaps", barFname)), IsNil)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+ - name: bar
+   file: %s
+`, coreFname, kernelFname, gadgetFname, fooFname, barFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	for _, aggregate := range []bool{false, true} {
+		opts := &devicestate.PopulateStateFromSeedOptions{AggregateErrors: aggregate}
+		tsAll, err := devicestate.PopulateStateFromSeed(st, opts)
+		c.Assert(err, ErrorMatches, `cannot seed snap "bar": file ".*/snaps/`+barFname+`" not found`)
+		c.Check(tsAll, IsNil)
+		// whatever was created is not part of any change
+		c.Check(st.Changes(), HasLen, 0)
+		c.Check(st.Tasks(), HasLen, 0)
+	}
+}
+
+func (s *FirstBootTestSuite) writeSeedWithUnassertedSnap(c *C, modelName string) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	mockSnapFile := snaptest.MakeTestSnapWithFiles(c, "name: local\nversion: 1.0", nil)
+	localFname := filepath.Base(mockSnapFile)
+	err := os.Rename(mockSnapFile, filepath.Join(dirs.SnapSeedDir, "snaps", localFname))
+	c.Assert(err, IsNil)
+
+	assertsChain := s.makeModelAssertionChain(c, modelName)
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: local
+   file: %s
+   unasserted: true
+`, coreFname, kernelFname, gadgetFname, localFname))
+	err = ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedUnassertedSnapSignedGrade(c *C) {
+	s.writeSeedWithUnassertedSnap(c, "my-model-signed")
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, `cannot seed unasserted snap "local" with a model of grade "signed"`)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedUnassertedSnapDangerousGrade(c *C) {
+	s.writeSeedWithUnassertedSnap(c, "my-model-dangerous")
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+	// core, kernel, gadget, their configuration, local and mark-seeded
+	c.Check(tsAll, HasLen, 8)
+}
+
+func (s *FirstBootTestSuite) makeStoreAssertion(c *C, storeName, url string) asserts.Assertion {
+	store, err := s.storeSigning.Sign(asserts.StoreType, map[string]interface{}{
+		"store":       storeName,
+		"operator-id": "can0nical",
+		"url":         url,
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	return store
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedStoreAssertion(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+	// the model uses the "canonical" store
+	store := s.makeStoreAssertion(c, "canonical", "https://proxy.example.com")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "store.asserts", []asserts.Assertion{store})
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	c.Check(storestate.BaseURL(st), Equals, "")
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+	c.Check(storestate.BaseURL(st), Equals, "https://proxy.example.com")
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedCohort(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, "name: foo\nversion: 1.0", nil, snap.R(128), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+   channel: stable
+   cohort: some-cohort-key
+`, coreFname, kernelFname, gadgetFname, fooFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+	// core, kernel, gadget, their configuration, foo and mark-seeded
+	c.Assert(tsAll, HasLen, 8)
+
+	snapsup, err := snapstate.TaskSnapSetup(tsAll[6].Tasks()[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.Name(), Equals, "foo")
+	c.Check(snapsup.Channel, Equals, "stable")
+	c.Check(snapsup.CohortKey, Equals, "some-cohort-key")
+
+	// no cohort for the others
+	snapsup, err = snapstate.TaskSnapSetup(tsAll[0].Tasks()[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.Name(), Equals, "core")
+	c.Check(snapsup.CohortKey, Equals, "")
+}
+
+func (s *FirstBootTestSuite) TestUnseededSnaps(c *C) {
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	seed := &snap.Seed{Snaps: []*snap.SeedSnap{
+		{Name: "core"},
+		{Name: "pc-kernel"},
+		{Name: "pc"},
+		{Name: "foo"},
+		{Name: "bar"},
+	}}
+
+	// nothing installed yet
+	unseeded, err := devicestate.UnseededSnaps(st, seed)
+	c.Assert(err, IsNil)
+	c.Check(unseeded, DeepEquals, seed.Snaps)
+
+	// partially installed
+	for _, name := range []string{"core", "pc", "bar"} {
+		snapstate.Set(st, name, &snapstate.SnapState{
+			Active:   true,
+			Sequence: []*snap.SideInfo{{RealName: name, Revision: snap.R(1)}},
+			Current:  snap.R(1),
+		})
+	}
+	unseeded, err = devicestate.UnseededSnaps(st, seed)
+	c.Assert(err, IsNil)
+	c.Check(unseeded, DeepEquals, []*snap.SeedSnap{seed.Snaps[1], seed.Snaps[3]})
+
+	// all installed
+	for _, name := range []string{"pc-kernel", "foo"} {
+		snapstate.Set(st, name, &snapstate.SnapState{
+			Active:   true,
+			Sequence: []*snap.SideInfo{{RealName: name, Revision: snap.R(1)}},
+			Current:  snap.R(1),
+		})
+	}
+	unseeded, err = devicestate.UnseededSnaps(st, seed)
+	c.Assert(err, IsNil)
+	c.Check(unseeded, HasLen, 0)
+}
+
+func (s *FirstBootTestSuite) TestOrderSeedSnaps(c *C) {
+	model := s.makeModelAssertion(c, "my-model")
+
+	seedSnaps := []*snap.SeedSnap{
+		{Name: "foo"},
+		{Name: "bar"},
+		{Name: "pc"},
+		{Name: "core18"},
+		{Name: "pc-kernel"},
+		{Name: "baz"},
+		{Name: "core"},
+	}
+	infos := map[string]*snap.Info{
+		"foo":    {SuggestedName: "foo", Base: "core18"},
+		"core18": {SuggestedName: "core18", Type: snap.TypeBase},
+		"baz":    {SuggestedName: "baz", Base: "core"},
+		// bar has no info, so it is taken to not use a base
+	}
+
+	order, err := devicestate.OrderSeedSnaps(seedSnaps, infos, model)
+	c.Assert(err, IsNil)
+	var names []string
+	for _, sn := range order {
+		names = append(names, sn.Name)
+	}
+	c.Check(names, DeepEquals, []string{"core", "pc-kernel", "pc", "core18", "foo", "bar", "baz"})
+}
+
+func (s *FirstBootTestSuite) TestOrderSeedSnapsMissingBase(c *C) {
+	model := s.makeModelAssertion(c, "my-model")
+
+	seedSnaps := []*snap.SeedSnap{
+		{Name: "core"},
+		{Name: "pc-kernel"},
+		{Name: "pc"},
+		{Name: "foo"},
+	}
+	infos := map[string]*snap.Info{
+		"foo": {SuggestedName: "foo", Base: "core18"},
+	}
+
+	_, err := devicestate.OrderSeedSnaps(seedSnaps, infos, model)
+	c.Assert(err, ErrorMatches, `cannot use snap "foo": base "core18" is missing from the seed`)
+}
+
+func (s *FirstBootTestSuite) TestOrderSeedSnapsBaseCycle(c *C) {
+	model := s.makeModelAssertion(c, "my-model")
+
+	seedSnaps := []*snap.SeedSnap{
+		{Name: "core"},
+		{Name: "base-a"},
+		{Name: "base-b"},
+	}
+	infos := map[string]*snap.Info{
+		"base-a": {SuggestedName: "base-a", Type: snap.TypeBase, Base: "base-b"},
+		"base-b": {SuggestedName: "base-b", Type: snap.TypeBase, Base: "base-a"},
+	}
+
+	_, err := devicestate.OrderSeedSnaps(seedSnaps, infos, model)
+	c.Assert(err, ErrorMatches, `cannot order seed snaps: snap "base-a" is part of a cycle of bases`)
+}
+
+func (s *FirstBootTestSuite) TestOrderSeedSnapsContentProviders(c *C) {
+	model := s.makeModelAssertion(c, "my-model")
+
+	seedSnaps := []*snap.SeedSnap{
+		{Name: "core"},
+		{Name: "pc-kernel"},
+		{Name: "pc"},
+		{Name: "consumer"},
+		{Name: "other"},
+		{Name: "provider"},
+	}
+	infos := map[string]*snap.Info{
+		"consumer": {
+			SuggestedName: "consumer",
+			Plugs: map[string]*snap.PlugInfo{
+				"themes": {Name: "themes", Interface: "content", Attrs: map[string]interface{}{"content": "gtk-3-themes"}},
+			},
+		},
+		"provider": {
+			SuggestedName: "provider",
+			Slots: map[string]*snap.SlotInfo{
+				"gtk-3-themes": {Name: "gtk-3-themes", Interface: "content"},
+			},
+		},
+	}
+
+	order, err := devicestate.OrderSeedSnaps(seedSnaps, infos, model)
+	c.Assert(err, IsNil)
+	var names []string
+	for _, sn := range order {
+		names = append(names, sn.Name)
+	}
+	c.Check(names, DeepEquals, []string{"core", "pc-kernel", "pc", "provider", "consumer", "other"})
+}
+
+func (s *FirstBootTestSuite) TestOrderSeedSnapsContentProvidersOfEachOther(c *C) {
+	model := s.makeModelAssertion(c, "my-model")
+
+	seedSnaps := []*snap.SeedSnap{
+		{Name: "core"},
+		{Name: "foo"},
+		{Name: "bar"},
+	}
+	infos := map[string]*snap.Info{
+		"foo": {
+			SuggestedName: "foo",
+			Plugs:         map[string]*snap.PlugInfo{"bar-data": {Name: "bar-data", Interface: "content"}},
+			Slots:         map[string]*snap.SlotInfo{"foo-data": {Name: "foo-data", Interface: "content"}},
+		},
+		"bar": {
+			SuggestedName: "bar",
+			Plugs:         map[string]*snap.PlugInfo{"foo-data": {Name: "foo-data", Interface: "content"}},
+			Slots:         map[string]*snap.SlotInfo{"bar-data": {Name: "bar-data", Interface: "content"}},
+		},
+	}
+
+	order, err := devicestate.OrderSeedSnaps(seedSnaps, infos, model)
+	c.Assert(err, IsNil)
+	var names []string
+	for _, sn := range order {
+		names = append(names, sn.Name)
+	}
+	c.Check(names, DeepEquals, []string{"core", "bar", "foo"})
+}
+
+func (s *FirstBootTestSuite) TestOrderSeedSnapsPreferredContentProvider(c *C) {
+	model := s.makeModelAssertion(c, "my-model")
+
+	seedSnaps := []*snap.SeedSnap{
+		{Name: "core"},
+		{Name: "alternative"},
+		{Name: "consumer"},
+		{Name: "preferred", Prefer: true},
+		{Name: "other"},
+	}
+	providerInfo := func(name string) *snap.Info {
+		return &snap.Info{
+			SuggestedName: name,
+			Slots:         map[string]*snap.SlotInfo{"themes": {Name: "themes", Interface: "content"}},
+		}
+	}
+	infos := map[string]*snap.Info{
+		"alternative": providerInfo("alternative"),
+		"preferred":   providerInfo("preferred"),
+		"consumer": {
+			SuggestedName: "consumer",
+			Plugs:         map[string]*snap.PlugInfo{"themes": {Name: "themes", Interface: "content"}},
+		},
+	}
+
+	order, err := devicestate.OrderSeedSnaps(seedSnaps, infos, model)
+	c.Assert(err, IsNil)
+	var names []string
+	for _, sn := range order {
+		names = append(names, sn.Name)
+	}
+	// the consumer goes after the preferred provider, and the
+	// alternative one only after everything else
+	c.Check(names, DeepEquals, []string{"core", "preferred", "consumer", "other", "alternative"})
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedContentProviders(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	writeSeedSnap := func(snapYaml string) string {
+		mockSnapFile := snaptest.MakeTestSnapWithFiles(c, snapYaml, nil)
+		fname := filepath.Base(mockSnapFile)
+		err := os.Rename(mockSnapFile, filepath.Join(dirs.SnapSeedDir, "snaps", fname))
+		c.Assert(err, IsNil)
+		return fname
+	}
+	consumerFname := writeSeedSnap(`name: consumer
+version: 1.0
+plugs:
+ themes:
+  interface: content
+  content: gtk-3-themes
+  target: $SNAP/themes`)
+	providerFname := writeSeedSnap(`name: provider
+version: 1.0
+slots:
+ gtk-3-themes:
+  interface: content
+  read: [$SNAP/share/themes]`)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	// the consumer is listed before its provider on purpose
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: consumer
+   file: %s
+   unasserted: true
+ - name: provider
+   file: %s
+   unasserted: true
+`, coreFname, kernelFname, gadgetFname, consumerFname, providerFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+	c.Assert(tsAll, HasLen, 9)
+
+	// the consumer is set up after its provider and waits for it
+	providerTasks := tsAll[6].Tasks()
+	consumerTasks := tsAll[7].Tasks()
+	providerSnapsup, err := snapstate.TaskSnapSetup(providerTasks[0])
+	c.Assert(err, IsNil)
+	c.Check(providerSnapsup.Name(), Equals, "provider")
+	consumerSnapsup, err := snapstate.TaskSnapSetup(consumerTasks[0])
+	c.Assert(err, IsNil)
+	c.Check(consumerSnapsup.Name(), Equals, "consumer")
+	c.Check(consumerTasks[0].WaitTasks(), testutil.Contains, providerTasks[len(providerTasks)-1])
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedPreferredContentProvider(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	writeSeedSnap := func(snapYaml string) string {
+		mockSnapFile := snaptest.MakeTestSnapWithFiles(c, snapYaml, nil)
+		fname := filepath.Base(mockSnapFile)
+		err := os.Rename(mockSnapFile, filepath.Join(dirs.SnapSeedDir, "snaps", fname))
+		c.Assert(err, IsNil)
+		return fname
+	}
+	consumerFname := writeSeedSnap(`name: consumer
+version: 1.0
+plugs:
+ themes:
+  interface: content
+  content: gtk-3-themes
+  target: $SNAP/themes`)
+	providerYaml := `name: %s
+version: 1.0
+slots:
+ gtk-3-themes:
+  interface: content
+  read: [$SNAP/share/themes]`
+	alternativeFname := writeSeedSnap(fmt.Sprintf(providerYaml, "alternative"))
+	preferredFname := writeSeedSnap(fmt.Sprintf(providerYaml, "preferred"))
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: alternative
+   file: %s
+   unasserted: true
+ - name: consumer
+   file: %s
+   unasserted: true
+ - name: preferred
+   file: %s
+   unasserted: true
+   prefer: true
+`, coreFname, kernelFname, gadgetFname, alternativeFname, consumerFname, preferredFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+
+	tasks := make(map[string][]*state.Task)
+	for _, ts := range tsAll {
+		if snapsup, err := snapstate.TaskSnapSetup(ts.Tasks()[0]); err == nil {
+			tasks[snapsup.Name()] = ts.Tasks()
+		}
+	}
+	last := func(name string) *state.Task {
+		return tasks[name][len(tasks[name])-1]
+	}
+
+	// the consumer waits for the preferred provider only, so that it
+	// gets connected to it, and the alternative provider comes later
+	c.Check(tasks["consumer"][0].WaitTasks(), testutil.Contains, last("preferred"))
+	c.Check(tasks["consumer"][0].WaitTasks(), Not(testutil.Contains), last("alternative"))
+	c.Check(tasks["alternative"][0].WaitTasks(), testutil.Contains, last("consumer"))
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedHold(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	var fnames []string
+	for _, name := range []string{"foo", "bar"} {
+		snapYaml := fmt.Sprintf("name: %s\nversion: 1.0\napps:\n svc:\n  daemon: simple", name)
+		fnames = append(fnames, devicestatetest.MakeSeedSnap(c, dirs.SnapSeedDir, snapYaml))
+	}
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+   unasserted: true
+   hold: true
+ - name: bar
+   file: %s
+   unasserted: true
+`, coreFname, kernelFname, gadgetFname, fnames[0], fnames[1]))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+
+	configures := make(map[string]bool)
+	installs := make(map[string]bool)
+	starts := make(map[string]bool)
+	for _, ts := range tsAll {
+		// the first task of an install task set carries the snap setup
+		snapsup, err := snapstate.TaskSnapSetup(ts.Tasks()[0])
+		if err == nil {
+			installs[snapsup.Name()] = true
+		}
+		for _, t := range ts.Tasks() {
+			if t.Kind() == "start-snap-services" {
+				starts[snapsup.Name()] = true
+			}
+			if t.Kind() == "run-hook" {
+				var hooksup hookstate.HookSetup
+				if err := t.Get("hook-setup", &hooksup); err == nil && hooksup.Hook == "configure" {
+					configures[hooksup.Snap] = true
+				}
+			}
+		}
+	}
+	// the held snap is installed but neither configured nor are
+	// its services started
+	c.Check(installs["foo"], Equals, true)
+	c.Check(configures["foo"], Equals, false)
+	c.Check(starts["foo"], Equals, false)
+	c.Check(installs["bar"], Equals, true)
+	c.Check(configures["bar"], Equals, true)
+	c.Check(starts["bar"], Equals, true)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedHoldEssential(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+   hold: true
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, `cannot hold essential snap "pc-kernel"`)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedGadgetDefaultsForUnseededSnap(c *C) {
+	// the gadget has configuration defaults for foo too
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, true)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, `cannot use gadget "pc" configuration defaults for snaps not in the seed: foo-snap-id`)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedGadgetDefaultsForInstalledCoreAndSystem(c *C) {
+	release.OnClassic = true
+
+	gadgetYaml := `
+volumes:
+    volume-id:
+        bootloader: grub
+defaults:
+    core-snap-id:
+       core-cfg: core_cfg_defl
+    system:
+       service.rsyslog.disable: true
+`
+	gadgetFname, gadgetDecl, gadgetRev := s.makeAssertedSnap(c, "name: pc\nversion: 1.0\ntype: gadget", [][]string{{"meta/gadget.yaml", gadgetYaml}}, snap.R(1), "canonical")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "gadget.asserts", []asserts.Assertion{gadgetRev, gadgetDecl})
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model-classic")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: pc
+   file: %s
+`, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// core is not in the seed but installed already
+	snapstate.Set(st, "core", &snapstate.SnapState{
+		Active: true,
+		Sequence: []*snap.SideInfo{
+			{RealName: "core", SnapID: "core-snap-id", Revision: snap.R(1)},
+		},
+		Current:  snap.R(1),
+		SnapType: "os",
+	})
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedGadgetDefaultsSideInfoError(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, true)
+
+	// no assertions for bar
+	barFname := devicestatetest.MakeSeedSnap(c, dirs.SnapSeedDir, "name: bar\nversion: 1.0")
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: bar
+   file: %s
+`, coreFname, kernelFname, gadgetFname, barFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, `cannot find signatures with metadata for snap "bar" .*`)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedLogs(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	mockSnapFile := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 1.0", nil)
+	fooFname := filepath.Base(mockSnapFile)
+	err := os.Rename(mockSnapFile, filepath.Join(dirs.SnapSeedDir, "snaps", fooFname))
+	c.Assert(err, IsNil)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+   unasserted: true
+`, coreFname, kernelFname, gadgetFname, fooFname))
+	err = ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+
+	logs := logbuf.String()
+	c.Check(logs, testutil.Contains, `seeding: imported model brand-id="my-brand" model="my-model"`)
+	c.Check(logs, testutil.Contains, `seeding: queued core snap name="core" revision=1`)
+	c.Check(logs, testutil.Contains, `seeding: queued kernel snap name="pc-kernel" revision=1`)
+	c.Check(logs, testutil.Contains, `seeding: queued gadget snap name="pc" revision=1`)
+	c.Check(logs, testutil.Contains, `seeding: queued snap name="foo" revision=unset`)
+	// each snap is logged once
+	c.Check(strings.Count(logs, `name="core"`), Equals, 1)
+	c.Check(strings.Count(logs, `name="pc-kernel"`), Equals, 1)
+	c.Check(strings.Count(logs, `name="pc"`), Equals, 1)
+	c.Check(logs, testutil.Contains, fmt.Sprintf("seeding: appended mark-seeded task-sets=%d", len(tsAll)-1))
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedKernelTrack(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	writeSeed := func(modelName, kernelChannel string) {
+		assertsChain := s.makeModelAssertionChain(c, modelName)
+		devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+		content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+   channel: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, kernelChannel, gadgetFname))
+		err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+		c.Assert(err, IsNil)
+	}
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	for _, t := range []struct {
+		model, channel string
+		err            string
+	}{
+		// unconstrained models work with any channel
+		{"my-model", "stable", ""},
+		{"my-model", "4.4/edge", ""},
+		// otherwise the kernel must come from the track of the model
+		{"my-model-kernel-track", "18/stable", ""},
+		{"my-model-kernel-track", "18/beta/hotfix", ""},
+		{"my-model-kernel-track", "stable", `cannot seed snap "pc-kernel" from channel "stable", the model requires track "18"`},
+		{"my-model-kernel-track", "4.4/stable", `cannot seed snap "pc-kernel" from channel "4.4/stable", the model requires track "18"`},
+	} {
+		writeSeed(t.model, t.channel)
+		_, err := devicestate.PopulateStateFromSeedImpl(st)
+		comm := Commentf("%s %s", t.model, t.channel)
+		if t.err == "" {
+			c.Check(err, IsNil, comm)
+		} else {
+			c.Check(err, ErrorMatches, t.err, comm)
+		}
+	}
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedMissingBase(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	mockSnapFile := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 1.0\nbase: core18", nil)
+	fooFname := filepath.Base(mockSnapFile)
+	err := os.Rename(mockSnapFile, filepath.Join(dirs.SnapSeedDir, "snaps", fooFname))
+	c.Assert(err, IsNil)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+   unasserted: true
+`, coreFname, kernelFname, gadgetFname, fooFname))
+	err = ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, `cannot use snap "foo": base "core18" is missing from the seed`)
+	// nothing but mark-seeded was created
+	c.Check(st.TaskCount(), Equals, 1)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedMissingRequiredSnap(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model", "foo")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	// foo is required but missing
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, `cannot proceed, model requires snap "foo" not present in seed`)
+	// nothing but mark-seeded was created
+	c.Check(st.TaskCount(), Equals, 1)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedMoreThanOneKernelOrGadget(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+	otherKernelFname := devicestatetest.MakeSeedSnap(c, dirs.SnapSeedDir, "name: other-kernel\nversion: 1.0\ntype: kernel")
+	otherGadgetFname := devicestatetest.MakeSeedSnap(c, dirs.SnapSeedDir, "name: other-gadget\nversion: 1.0\ntype: gadget")
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	for _, t := range []struct {
+		name, fname string
+		err         string
+	}{
+		{"other-kernel", otherKernelFname, `cannot seed more than one kernel snap: model uses "pc-kernel" but seed also has "other-kernel"`},
+		{"other-gadget", otherGadgetFname, `cannot seed more than one gadget snap: model uses "pc" but seed also has "other-gadget"`},
+		{"pc-kernel", kernelFname, `seed.yaml contains duplicate entry for snap "pc-kernel"`},
+		{"pc", gadgetFname, `seed.yaml contains duplicate entry for snap "pc"`},
+	} {
+		content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: %s
+   file: %s
+   unasserted: true
+`, coreFname, kernelFname, gadgetFname, t.name, t.fname))
+		err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+		c.Assert(err, IsNil)
+
+		_, err = devicestate.PopulateStateFromSeedImpl(st)
+		c.Check(err, ErrorMatches, t.err, Commentf(t.name))
+		c.Check(devicestate.ValidateSeed(dirs.SnapSeedDir), ErrorMatches, `(?s).*`+regexp.QuoteMeta(t.err)+`.*`, Commentf(t.name))
+	}
+	// nothing but a mark-seeded task for each attempt was created
+	c.Check(st.TaskCount(), Equals, 4)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedPrepareDevice(c *C) {
+	coreFname, kernelFname, _ := s.makeCoreSnaps(c, false)
+
+	// a gadget with a prepare-device hook
+	files := [][]string{
+		{"meta/gadget.yaml", "volumes:\n    volume-id:\n        bootloader: grub\n"},
+		{"meta/hooks/prepare-device", ""},
+	}
+	gadgetFname, gadgetDecl, gadgetRev := s.makeAssertedSnap(c, "name: pc\nversion: 1.0\ntype: gadget", files, snap.R(1), "canonical")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "gadget.asserts", []asserts.Assertion{gadgetRev, gadgetDecl})
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	findPrepareDevice := func(tsAll []*state.TaskSet) (int, *state.Task) {
+		for i, ts := range tsAll {
+			for _, t := range ts.Tasks() {
+				var hooksup hookstate.HookSetup
+				if t.Kind() == "run-hook" && t.Get("hook-setup", &hooksup) == nil && hooksup.Hook == "prepare-device" {
+					return i, t
+				}
+			}
+		}
+		return -1, nil
+	}
+
+	// only when asked for
+	tsAll, err := devicestate.PopulateStateFromSeed(st, nil)
+	c.Assert(err, IsNil)
+	_, t := findPrepareDevice(tsAll)
+	c.Check(t, IsNil)
+
+	tsAll, err = devicestate.PopulateStateFromSeed(st, &devicestate.PopulateStateFromSeedOptions{
+		PrepareDevice: true,
+	})
+	c.Assert(err, IsNil)
+	i, prepareDevice := findPrepareDevice(tsAll)
+	c.Assert(prepareDevice, NotNil)
+
+	var hooksup hookstate.HookSetup
+	c.Assert(prepareDevice.Get("hook-setup", &hooksup), IsNil)
+	c.Check(hooksup.Snap, Equals, "pc")
+
+	// after installing and configuring the gadget
+	configureGadget := tsAll[i-1].Tasks()
+	c.Assert(prepareDevice.WaitTasks(), DeepEquals, []*state.Task{configureGadget[len(configureGadget)-1]})
+	c.Assert(tsAll[i-1].Tasks()[0].Kind(), Equals, "run-hook")
+	c.Assert(tsAll[i-1].Tasks()[0].Get("hook-setup", &hooksup), IsNil)
+	c.Check(hooksup.Snap, Equals, "pc")
+	c.Check(hooksup.Hook, Equals, "configure")
+
+	// and before marking the device seeded
+	markSeeded := tsAll[len(tsAll)-1].Tasks()[0]
+	c.Check(markSeeded.Kind(), Equals, "mark-seeded")
+	c.Check(markSeeded.WaitTasks(), testutil.Contains, prepareDevice)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedGadgetFirst(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model-gadget-first")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// the order comes from the model, as when seeding for real
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+	// core, gadget, kernel, their configure task sets and mark-seeded
+	c.Assert(tsAll, HasLen, 7)
+
+	snapName := func(ts *state.TaskSet) string {
+		snapsup, err := snapstate.TaskSnapSetup(ts.Tasks()[0])
+		c.Assert(err, IsNil)
+		return snapsup.Name()
+	}
+	c.Check(snapName(tsAll[1]), Equals, "pc")
+	c.Check(snapName(tsAll[2]), Equals, "pc-kernel")
+
+	// the gadget is also configured before the kernel
+	gadgetConfigure := tsAll[4].Tasks()
+	kernelConfigure := tsAll[5].Tasks()
+	c.Check(kernelConfigure[0].WaitTasks(), testutil.Contains, gadgetConfigure[len(gadgetConfigure)-1])
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedAggregateErrors(c *C) {
+	coreFname, kernelFname, _ := s.makeCoreSnaps(c, false)
+
+	// neither foo nor bar have assertions in the seed
+	fooFname, _, _ := s.makeAssertedSnap(c, "name: foo\nversion: 1.0", nil, snap.R(128), "developerid")
+	barFname, _, _ := s.makeAssertedSnap(c, "name: bar\nversion: 1.0", nil, snap.R(65), "developerid")
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	// the gadget is missing
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: foo
+   file: %s
+ - name: bar
+   file: %s
+`, coreFname, kernelFname, fooFname, barFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// by default the first problem is reported
+	_, err = devicestate.PopulateStateFromSeed(st, nil)
+	c.Assert(err, ErrorMatches, `cannot find seed information for gadget snap "pc"`)
+
+	_, err = devicestate.PopulateStateFromSeed(st, &devicestate.PopulateStateFromSeedOptions{AggregateErrors: true})
+	c.Assert(err, ErrorMatches, `cannot seed:
+- cannot find seed information for gadget snap "pc"
+- cannot find signatures with metadata for snap "foo" .*
+- cannot find signatures with metadata for snap "bar" .*`)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedAlternateSeedDir(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	// move the seed out of the way
+	seedDir := filepath.Join(c.MkDir(), "recovery-seed")
+	err = os.Rename(dirs.SnapSeedDir, seedDir)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, "cannot read assert seed dir: .*")
+
+	tsAll, err := devicestate.PopulateStateFromSeed(st, &devicestate.PopulateStateFromSeedOptions{SeedDir: seedDir})
+	c.Assert(err, IsNil)
+	// core, kernel, gadget, their configure task sets and mark-seeded
+	c.Check(tsAll, HasLen, 7)
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedReseed(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+
+	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, "name: foo\nversion: 1.0", nil, snap.R(128), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+
+	barFname, barDecl, barRev := s.makeAssertedSnap(c, "name: bar\nversion: 1.0", nil, snap.R(65), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "bar.asserts", []asserts.Assertion{barDecl, barRev})
+
+	// the model now requires foo
+	assertsChain := s.makeModelAssertionChain(c, "my-model", "foo")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+ - name: bar
+   file: %s
+`, coreFname, kernelFname, gadgetFname, fooFname, barFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	st.Set("seeded", true)
+	for _, name := range []string{"core", "pc-kernel", "pc"} {
+		snapstate.Set(st, name, &snapstate.SnapState{
+			Active:   true,
+			Sequence: []*snap.SideInfo{{RealName: name, Revision: snap.R(1)}},
+			Current:  snap.R(1),
+		})
+	}
+
+	_, err = devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, ErrorMatches, "cannot populate state: already seeded")
+
+	tsAll, err := devicestate.PopulateStateFromSeed(st, &devicestate.PopulateStateFromSeedOptions{Reseed: true})
+	c.Assert(err, IsNil)
+	// only foo is installed, bar is not required
+	c.Assert(tsAll, HasLen, 2)
+	c.Check(tsAll[0].Tasks()[0].Summary(), Equals, `Seed snap "foo" (revision 128, 1 of 1)`)
+	c.Check(tsAll[1].Tasks()[0].Kind(), Equals, "mark-seeded")
+
+	// with foo installed there is nothing left to do
+	snapstate.Set(st, "foo", &snapstate.SnapState{
+		Active:   true,
+		Sequence: []*snap.SideInfo{{RealName: "foo", Revision: snap.R(128)}},
+		Current:  snap.R(128),
+	})
+	tsAll, err = devicestate.PopulateStateFromSeed(st, &devicestate.PopulateStateFromSeedOptions{Reseed: true})
+	c.Assert(err, IsNil)
+	c.Assert(tsAll, HasLen, 1)
+	c.Check(tsAll[0].Tasks()[0].Kind(), Equals, "mark-seeded")
+}
+
+func (s *FirstBootTestSuite) TestPopulateFromSeedResumesInterrupted(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+
+	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, "name: foo\nversion: 1.0", nil, snap.R(128), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+
+	barFname, barDecl, barRev := s.makeAssertedSnap(c, "name: bar\nversion: 1.0", nil, snap.R(65), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "bar.asserts", []asserts.Assertion{barDecl, barRev})
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+ - name: foo
+   file: %s
+ - name: bar
+   file: %s
+`, coreFname, kernelFname, gadgetFname, fooFname, barFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// seeding got as far as installing core, kernel, gadget and
+	// foo, while bar is there only with some other revision
+	installed := map[string]snap.Revision{
+		"core":      snap.R(1),
+		"pc-kernel": snap.R(1),
+		"pc":        snap.R(1),
+		"foo":       snap.R(128),
+		"bar":       snap.R(64),
+	}
+	for name, rev := range installed {
+		snapstate.Set(st, name, &snapstate.SnapState{
+			Active:   true,
+			Sequence: []*snap.SideInfo{{RealName: name, Revision: rev}},
+			Current:  rev,
+		})
+	}
+
+	tsAll, err := devicestate.PopulateStateFromSeedImpl(st)
+	c.Assert(err, IsNil)
+
+	var installs []string
+	configures := 0
+	for _, ts := range tsAll {
+		if snapsup, err := snapstate.TaskSnapSetup(ts.Tasks()[0]); err == nil {
+			installs = append(installs, snapsup.Name())
+		}
+		for _, t := range ts.Tasks() {
+			if t.Kind() == "run-hook" {
+				var hooksup hookstate.HookSetup
+				if err := t.Get("hook-setup", &hooksup); err == nil && hooksup.Hook == "configure" {
+					configures++
+				}
+			}
+		}
+	}
+	// only bar is installed again, while core, kernel and gadget
+	// still get configured
+	c.Check(installs, DeepEquals, []string{"bar"})
+	c.Check(configures, Equals, 4)
+	c.Check(tsAll[0].Tasks()[0].Kind(), Equals, "run-hook")
+	last := tsAll[len(tsAll)-1].Tasks()
+	c.Check(last[len(last)-1].Kind(), Equals, "mark-seeded")
+}
+
+func (s *FirstBootTestSuite) TestInstallSeedSnapSideInfo(c *C) {
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+
+	fooFname, fooDecl, fooRev := s.makeAssertedSnap(c, "name: foo\nversion: 1.0", nil, snap.R(128), "developerid")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.asserts", []asserts.Assertion{devAcct, fooRev, fooDecl})
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	_, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, IsNil)
+
+	sn := &snap.SeedSnap{Name: "foo", File: fooFname}
+
+	// without a side info it is derived from the assertions
+	ts, info, err := devicestate.InstallSeedSnap(st, dirs.SnapSeedDir, sn, nil, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	c.Check(info.Name(), Equals, "foo")
+	snapsup, err := snapstate.TaskSnapSetup(ts.Tasks()[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.SideInfo.Revision, Equals, snap.R(128))
+	c.Check(snapsup.SideInfo.SnapID, Equals, "foo-snap-id")
+
+	// a given side info is used as is
+	sideInfo := &snap.SideInfo{RealName: "foo", SnapID: "foo-snap-id", Revision: snap.R(42)}
+	ts, _, err = devicestate.InstallSeedSnap(st, dirs.SnapSeedDir, sn, sideInfo, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	snapsup, err = snapstate.TaskSnapSetup(ts.Tasks()[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.SideInfo, DeepEquals, sideInfo)
+}
+
+func (s *FirstBootTestSuite) TestValidateSeedHappy(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	err = devicestate.ValidateSeed(dirs.SnapSeedDir)
+	c.Assert(err, IsNil)
+
+	// the system state was not touched
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+	ds, err := auth.Device(st)
+	c.Assert(err, IsNil)
+	c.Check(ds.Brand, Equals, "")
+	c.Check(ds.Model, Equals, "")
+	_, err = assertstate.DB(st).Find(asserts.ModelType, map[string]string{
+		"series":   "16",
+		"brand-id": "my-brand",
+		"model":    "my-model",
+	})
+	c.Check(asserts.IsNotFound(err), Equals, true)
+}
+
+func (s *FirstBootTestSuite) TestValidateSeedReportsAllProblems(c *C) {
+	coreFname, kernelFname, _ := s.makeCoreSnaps(c, false)
+
+	// foo has no assertions in the seed
+	snapYaml := `name: foo
+version: 1.0`
+	fooFname, _, _ := s.makeAssertedSnap(c, snapYaml, nil, snap.R(128), "developerid")
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	// the gadget is missing
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: foo
+   file: %s
+`, coreFname, kernelFname, fooFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	err = devicestate.ValidateSeed(dirs.SnapSeedDir)
+	c.Assert(err, ErrorMatches, `cannot validate seed:
+- cannot find seed information for gadget snap "pc"
+- cannot find signatures with metadata for snap "foo" .*`)
+}
+
+func (s *FirstBootTestSuite) TestValidateSeedUnassertedSnapSignedGrade(c *C) {
+	s.writeSeedWithUnassertedSnap(c, "my-model-signed")
+
+	err := devicestate.ValidateSeed(dirs.SnapSeedDir)
+	c.Assert(err, ErrorMatches, `cannot seed unasserted snap "local" with a model of grade "signed"`)
+}
+
+func (s *FirstBootTestSuite) TestValidateSeedReportsSeveralProblemsWithOneSnap(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model-signed")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	// the kernel is both unasserted and held
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+   unasserted: true
+   hold: true
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	err = devicestate.ValidateSeed(dirs.SnapSeedDir)
+	c.Assert(err, ErrorMatches, `cannot validate seed:
+- cannot seed unasserted snap "pc-kernel" with a model of grade "signed"
+- cannot hold essential snap "pc-kernel"`)
+}
+
+func (s *FirstBootTestSuite) TestValidateSeedReportsAssertionsAndSeedYamlProblems(c *C) {
+	// two different model assertions
+	model := s.makeModelAssertion(c, "my-model")
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "assertions", "model"), asserts.Encode(model), 0644)
+	c.Assert(err, IsNil)
+	model2 := s.makeModelAssertion(c, "my-second-model")
+	err = ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "assertions", "model2"), asserts.Encode(model2), 0644)
+	c.Assert(err, IsNil)
+
+	// and a broken seed.yaml
+	content := []byte(`
+snaps:
+ - name: foo
+   file: foo_1.0_all.snap
+ - name: foo
+   file: foo_2.0_all.snap
+`)
+	err = ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	err = devicestate.ValidateSeed(dirs.SnapSeedDir)
+	c.Assert(err, ErrorMatches, `cannot validate seed:
+- cannot add more than one model assertion
+- seed.yaml contains duplicate entry for snap "foo"`)
+}
+
+func (s *FirstBootTestSuite) TestValidateSeedSerialWithoutDeviceKey(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	// building an image there is no device key yet
+	devKey, _ := assertstest.GenerateKey(752)
+	serial := s.makeSerialAssertion(c, "my-model", devKey)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "serial.asserts", []asserts.Assertion{serial})
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	err = devicestate.ValidateSeed(dirs.SnapSeedDir)
+	c.Assert(err, IsNil)
+}
+
+func (s *FirstBootTestSuite) TestValidateSeedIndependentOfHost(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	for _, t := range []struct {
+		model     string
+		onClassic bool
+		seedYaml  string
+		importErr string
+	}{
+		// a classic seed checked on a core host
+		{"my-model-classic", false, fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, gadgetFname), "cannot seed an all-snaps system with a classic model"},
+		// an all-snaps seed checked on a classic host
+		{"my-model", true, fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname), "cannot seed a classic system with an all-snaps model"},
+	} {
+		restore := release.MockOnClassic(t.onClassic)
+		defer restore()
+
+		assertsChain := s.makeModelAssertionChain(c, t.model)
+		devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+		err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), []byte(t.seedYaml), 0644)
+		c.Assert(err, IsNil)
+
+		c.Check(devicestate.ValidateSeed(dirs.SnapSeedDir), IsNil, Commentf(t.model))
+
+		// the seed cannot be used on this host though
+		st := s.overlord.State()
+		st.Lock()
+		_, err = devicestate.ImportAssertionsFromSeed(st)
+		st.Unlock()
+		c.Check(err, ErrorMatches, t.importErr, Commentf(t.model))
+	}
+}
+
+func (s *FirstBootTestSuite) TestValidateSeedOtherSeedDir(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	// the seed is checked where it is, not in the system seed dir
+	seedDir := filepath.Join(c.MkDir(), "seed")
+	err = os.Rename(dirs.SnapSeedDir, seedDir)
+	c.Assert(err, IsNil)
+
+	c.Check(devicestate.ValidateSeed(seedDir), IsNil)
+	c.Check(devicestate.ValidateSeed(dirs.SnapSeedDir), ErrorMatches, `(?s)cannot validate seed.*cannot read assert seed dir: .*`)
+}
+
+func (s *FirstBootTestSuite) TestSeedFromState(c *C) {
+	bootloader := boottest.NewMockBootloader("mock", c.MkDir())
+	partition.ForceBootloader(bootloader)
+	defer partition.ForceBootloader(nil)
+	bootloader.SetBootVars(map[string]string{
+		"snap_core":   "core_1.snap",
+		"snap_kernel": "pc-kernel_1.snap",
+	})
+
+	st := s.overlord.State()
+	chg := s.makeBecomeOperationalChange(c, st)
+	err := s.overlord.Settle(settleTimeout)
+	c.Assert(err, IsNil)
+
 	st.Lock()
+	defer st.Unlock()
 	c.Assert(chg.Err(), IsNil)
-	c.Assert(err, IsNil)
 
-	// and check the snap got correctly installed
-	c.Check(osutil.FileExists(filepath.Join(dirs.SnapMountDir, "foo", "128", "meta", "snap.yaml")), Equals, true)
+	seed, snapFiles, assertions, err := devicestate.SeedFromState(st)
+	c.Assert(err, IsNil)
 
-	// and check the snap got correctly installed
-	c.Check(osutil.FileExists(filepath.Join(dirs.SnapMountDir, "bar", "65", "meta", "snap.yaml")), Equals, true)
+	c.Assert(seed.Snaps, HasLen, 5)
+	c.Check(seed.Snaps[0].Name, Equals, "core")
+	c.Check(seed.Snaps[1].Name, Equals, "pc-kernel")
+	c.Check(seed.Snaps[2].Name, Equals, "pc")
+	c.Check(seed.Snaps[3], DeepEquals, &snap.SeedSnap{
+		Name:    "foo",
+		SnapID:  "foo-snap-id",
+		DevMode: true,
+		Contact: "mailto:some.guy@example.com",
+		File:    "foo_128.snap",
+	})
+	c.Check(seed.Snaps[4], DeepEquals, &snap.SeedSnap{
+		Name:       "local",
+		Unasserted: true,
+		File:       "local_x1.snap",
+	})
 
-	// verify
-	r, err := os.Open(dirs.SnapStateFile)
-	c.Assert(err, IsNil)
-	state, err := state.ReadState(nil, r)
-	c.Assert(err, IsNil)
+	c.Assert(snapFiles, HasLen, 5)
+	for i, fn := range snapFiles {
+		c.Check(filepath.Base(fn), Equals, seed.Snaps[i].File)
+		c.Check(osutil.FileExists(fn), Equals, true)
+	}
 
-	state.Lock()
-	defer state.Unlock()
-	// check foo
-	info, err := snapstate.CurrentInfo(state, "foo")
-	c.Assert(err, IsNil)
-	c.Check(info.SnapID, Equals, "foo-snap-id")
-	c.Check(info.Revision, Equals, snap.R(128))
-	pubAcct, err := assertstate.Publisher(st, info.SnapID)
+	// the model and the assertions of the asserted snaps are there,
+	// prerequisites first
+	seen := make(map[string]bool)
+	for _, a := range assertions {
+		for _, preref := range a.Prerequisites() {
+			if _, err := preref.Resolve(assertstate.DB(st).FindPredefined); err == nil {
+				continue
+			}
+			c.Check(seen[preref.Unique()], Equals, true, Commentf("%v before %v", preref, a.Ref()))
+		}
+		seen[a.Ref().Unique()] = true
+	}
+	model, err := devicestate.Model(st)
 	c.Assert(err, IsNil)
-	c.Check(pubAcct.AccountID(), Equals, "developerid")
+	c.Check(seen[model.Ref().Unique()], Equals, true)
+	revs := 0
+	for _, a := range assertions {
+		if a.Type() == asserts.SnapRevisionType {
+			revs++
+		}
+	}
+	// core, kernel, gadget and foo
+	c.Check(revs, Equals, 4)
 
-	// check bar
-	info, err = snapstate.CurrentInfo(state, "bar")
-	c.Assert(err, IsNil)
-	c.Check(info.SnapID, Equals, "bar-snap-id")
-	c.Check(info.Revision, Equals, snap.R(65))
-	pubAcct, err = assertstate.Publisher(st, info.SnapID)
+	// the seed can be written and read back
+	seedFn := filepath.Join(c.MkDir(), "seed.yaml")
+	c.Assert(seed.Write(seedFn), IsNil)
+	readSeed, err := snap.ReadSeedYaml(seedFn)
 	c.Assert(err, IsNil)
-	c.Check(pubAcct.AccountID(), Equals, "developerid")
+	c.Check(readSeed, DeepEquals, seed)
 }
 
 func (s *FirstBootTestSuite) makeModelAssertion(c *C, modelStr string, reqSnaps ...string) *asserts.Model {
@@ -613,11 +2911,28 @@ func (s *FirstBootTestSuite) makeModelAssertion(c *C, modelStr string, reqSnaps
 		"gadget":       "pc",
 		"timestamp":    time.Now().Format(time.RFC3339),
 	}
-	if strings.HasSuffix(modelStr, "-classic") {
+	if strings.HasPrefix(modelStr, "no-store-") {
+		delete(headers, "store")
+	}
+	switch {
+	case strings.HasSuffix(modelStr, "-classic"):
+		headers["classic"] = "true"
+	case strings.HasSuffix(modelStr, "-classic-no-gadget"):
 		headers["classic"] = "true"
-	} else {
+		delete(headers, "gadget")
+	case strings.HasSuffix(modelStr, "-kernel-track"):
+		headers["kernel"] = "pc-kernel=18"
+	default:
 		headers["kernel"] = "pc-kernel"
 	}
+	if strings.HasSuffix(modelStr, "-gadget-first") {
+		headers["gadget-first"] = "true"
+	}
+	for _, grade := range []string{"signed", "dangerous"} {
+		if strings.HasSuffix(modelStr, "-"+grade) {
+			headers["grade"] = grade
+		}
+	}
 	if len(reqSnaps) != 0 {
 		reqs := make([]interface{}, len(reqSnaps))
 		for i, req := range reqSnaps {
@@ -853,6 +3168,131 @@ func (s *FirstBootTestSuite) TestImportAssertionsFromSeedAllSnapsModelMismatch(c
 	c.Assert(err, ErrorMatches, "cannot seed an all-snaps system with a classic model")
 }
 
+func (s *FirstBootTestSuite) makeSerialAssertion(c *C, model string, devKey asserts.PrivateKey) *asserts.Serial {
+	encDevKey, err := asserts.EncodePublicKey(devKey.PublicKey())
+	c.Assert(err, IsNil)
+	serial, err := s.brandSigning.Sign(asserts.SerialType, map[string]interface{}{
+		"authority-id":        "my-brand",
+		"brand-id":            "my-brand",
+		"model":               model,
+		"serial":              "serialserial",
+		"device-key":          string(encDevKey),
+		"device-key-sha3-384": devKey.PublicKey().ID(),
+		"timestamp":           time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	return serial.(*asserts.Serial)
+}
+
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedSerial(c *C) {
+	st := s.overlord.State()
+
+	devKey, _ := assertstest.GenerateKey(752)
+	serial := s.makeSerialAssertion(c, "my-model", devKey)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "serial.asserts", []asserts.Assertion{serial})
+
+	st.Lock()
+	defer st.Unlock()
+
+	// without any device key the serial is not used
+	_, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, IsNil)
+	ds, err := auth.Device(st)
+	c.Assert(err, IsNil)
+	c.Check(ds.Serial, Equals, "")
+
+	// a device key other than the one of the serial
+	keypairMgr, err := asserts.OpenFSKeypairManager(dirs.SnapDeviceDir)
+	c.Assert(err, IsNil)
+	otherKey, _ := assertstest.GenerateKey(752)
+	c.Assert(keypairMgr.Put(otherKey), IsNil)
+	_, err = devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, ErrorMatches, `cannot seed with serial assertion "serialserial" without its device key: cannot find key pair`)
+
+	// it got provisioned with the serial
+	c.Assert(keypairMgr.Put(devKey), IsNil)
+
+	_, err = devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, IsNil)
+
+	ds, err = auth.Device(st)
+	c.Assert(err, IsNil)
+	c.Check(ds.Brand, Equals, "my-brand")
+	c.Check(ds.Model, Equals, "my-model")
+	c.Check(ds.Serial, Equals, "serialserial")
+	c.Check(ds.KeyID, Equals, devKey.PublicKey().ID())
+
+	_, err = assertstate.DB(st).Find(asserts.SerialType, map[string]string{
+		"brand-id": "my-brand",
+		"model":    "my-model",
+		"serial":   "serialserial",
+	})
+	c.Check(err, IsNil)
+}
+
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedSerialOtherModel(c *C) {
+	st := s.overlord.State()
+
+	devKey, _ := assertstest.GenerateKey(752)
+	serial := s.makeSerialAssertion(c, "other-model", devKey)
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "serial.asserts", []asserts.Assertion{serial})
+
+	st.Lock()
+	defer st.Unlock()
+
+	_, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, ErrorMatches, `cannot seed with serial assertion for model my-brand/other-model, the model is my-brand/my-model`)
+
+	ds, err := auth.Device(st)
+	c.Assert(err, IsNil)
+	c.Check(ds.Serial, Equals, "")
+}
+
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedUnsupportedFormat(c *C) {
+	st := s.overlord.State()
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	// a snap declaration with a format newer than supported
+	var snapDecl asserts.Assertion
+	(func() {
+		restore := asserts.MockMaxSupportedFormat(asserts.SnapDeclarationType, 999)
+		defer restore()
+		var err error
+		snapDecl, err = s.storeSigning.Sign(asserts.SnapDeclarationType, map[string]interface{}{
+			"format":       "999",
+			"series":       "16",
+			"snap-id":      "foo-snap-id",
+			"snap-name":    "foo",
+			"publisher-id": "can0nical",
+			"timestamp":    time.Now().UTC().Format(time.RFC3339),
+		}, nil, "")
+		c.Assert(err, IsNil)
+	})()
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "foo.snap-declaration", []asserts.Assertion{snapDecl})
+
+	st.Lock()
+	defer st.Unlock()
+
+	_, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, ErrorMatches, `seed requires a newer snapd \(assertion "snap-declaration" uses format 999\)`)
+
+	// nothing was added
+	_, err = assertstate.DB(st).Find(asserts.ModelType, map[string]string{
+		"series":   "16",
+		"brand-id": "my-brand",
+		"model":    "my-model",
+	})
+	c.Check(asserts.IsNotFound(err), Equals, true)
+}
+
 func (s *FirstBootTestSuite) TestImportAssertionsFromSeedHappy(c *C) {
 	ovld, err := overlord.New()
 	c.Assert(err, IsNil)
@@ -892,6 +3332,145 @@ func (s *FirstBootTestSuite) TestImportAssertionsFromSeedHappy(c *C) {
 
 	c.Check(model.BrandID(), Equals, "my-brand")
 	c.Check(model.Model(), Equals, "my-model")
+
+	// the imported assertions are recorded
+	var seedAsserts []string
+	err = st.Get("seed-assertions", &seedAsserts)
+	c.Assert(err, IsNil)
+	c.Assert(seedAsserts, HasLen, len(assertsChain))
+	for i, as := range assertsChain {
+		c.Check(seedAsserts[i], Equals, as.Ref().Unique())
+	}
+}
+
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedStoreForOtherStore(c *C) {
+	ovld, err := overlord.New()
+	c.Assert(err, IsNil)
+	st := ovld.State()
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+	store := s.makeStoreAssertion(c, "other-store", "https://proxy.example.com")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "store.asserts", []asserts.Assertion{store})
+
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, ErrorMatches, `cannot seed with store assertion for "other-store", the model uses store "canonical"`)
+}
+
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedStoreForModelWithoutStore(c *C) {
+	ovld, err := overlord.New()
+	c.Assert(err, IsNil)
+	st := ovld.State()
+
+	assertsChain := s.makeModelAssertionChain(c, "no-store-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+	store := s.makeStoreAssertion(c, "canonical", "https://proxy.example.com")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "store.asserts", []asserts.Assertion{store})
+
+	st.Lock()
+	defer st.Unlock()
+
+	_, err = devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, ErrorMatches, `cannot seed with store assertion for "canonical", the model does not use a store`)
+}
+
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedTwice(c *C) {
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	_, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, IsNil)
+
+	// importing the same assertions again is fine
+	model, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, IsNil)
+	c.Check(model.Model(), Equals, "my-model")
+}
+
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedEmptyFiles(c *C) {
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", assertsChain)
+
+	// empty files are skipped
+	for name, content := range map[string]string{
+		"empty":      "",
+		"whitespace": "\n  \n\n",
+	} {
+		err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "assertions", name), []byte(content), 0644)
+		c.Assert(err, IsNil)
+	}
+
+	model, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, IsNil)
+	c.Check(model.Model(), Equals, "my-model")
+
+	// but garbage is still an error
+	err = ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "assertions", "garbage"), []byte("garbage"), 0644)
+	c.Assert(err, IsNil)
+	_, err = devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, ErrorMatches, "cannot read assertions: .*")
+}
+
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedSubdirs(c *C) {
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// split the assertions across nested directories
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	for i, as := range assertsChain {
+		dir := filepath.Join(dirs.SnapSeedDir, "assertions", as.Type().Name, "nested")
+		err := os.MkdirAll(dir, 0755)
+		c.Assert(err, IsNil)
+		err = ioutil.WriteFile(filepath.Join(dir, strconv.Itoa(i)), asserts.Encode(as), 0644)
+		c.Assert(err, IsNil)
+	}
+
+	model, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, IsNil)
+	c.Check(model.Model(), Equals, "my-model")
+}
+
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedOutOfOrder(c *C) {
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	devAcct := assertstest.NewAccount(s.storeSigning, "developer", map[string]interface{}{
+		"account-id": "developerid",
+	}, "")
+	_, fooDecl, fooRev := s.makeAssertedSnap(c, "name: foo\nversion: 1.0", nil, snap.R(128), "developerid")
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+
+	// name the files so that assertions come before their
+	// prerequisites
+	all := append([]asserts.Assertion{devAcct, fooDecl, fooRev}, assertsChain...)
+	for i, as := range all {
+		fn := filepath.Join(dirs.SnapSeedDir, "assertions", strconv.Itoa(len(all)-i))
+		err := ioutil.WriteFile(fn, asserts.Encode(as), 0644)
+		c.Assert(err, IsNil)
+	}
+
+	_, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, IsNil)
+
+	// everything got added
+	db := assertstate.DB(st)
+	for _, as := range all {
+		_, err := as.Ref().Resolve(db.Find)
+		c.Check(err, IsNil)
+	}
 }
 
 func (s *FirstBootTestSuite) TestImportAssertionsFromSeedMissingSig(c *C) {
@@ -916,6 +3495,62 @@ func (s *FirstBootTestSuite) TestImportAssertionsFromSeedMissingSig(c *C) {
 	c.Assert(err, ErrorMatches, "cannot find account-key .*")
 }
 
+func (s *FirstBootTestSuite) TestPopulateFromSeedFetchMissingAssertions(c *C) {
+	coreFname, kernelFname, gadgetFname := s.makeCoreSnaps(c, false)
+
+	// the seed has only the model, the brand account and key are
+	// only available from the store
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	for _, as := range assertsChain {
+		switch as.Type() {
+		case asserts.ModelType:
+			devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model.asserts", []asserts.Assertion{as})
+		case asserts.AccountType, asserts.AccountKeyType:
+			err := s.storeSigning.Add(as)
+			if _, ok := err.(*asserts.RevisionError); !ok {
+				c.Assert(err, IsNil)
+			}
+		}
+	}
+
+	content := []byte(fmt.Sprintf(`
+snaps:
+ - name: core
+   file: %s
+ - name: pc-kernel
+   file: %s
+ - name: pc
+   file: %s
+`, coreFname, kernelFname, gadgetFname))
+	err := ioutil.WriteFile(filepath.Join(dirs.SnapSeedDir, "seed.yaml"), content, 0644)
+	c.Assert(err, IsNil)
+
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	storestate.ReplaceStore(st, &fakeStore{
+		state: st,
+		db:    s.storeSigning,
+	})
+
+	// by default the seed needs to be self-contained
+	_, err = devicestate.PopulateStateFromSeed(st, nil)
+	c.Assert(err, ErrorMatches, `cannot find account-key .*`)
+
+	tsAll, err := devicestate.PopulateStateFromSeed(st, &devicestate.PopulateStateFromSeedOptions{
+		FetchMissingAssertions: true,
+	})
+	c.Assert(err, IsNil)
+	c.Check(tsAll, Not(HasLen), 0)
+
+	db := assertstate.DB(st)
+	_, err = db.Find(asserts.AccountKeyType, map[string]string{
+		"public-key-sha3-384": s.brandPrivKey.PublicKey().ID(),
+	})
+	c.Check(err, IsNil)
+}
+
 func (s *FirstBootTestSuite) TestImportAssertionsFromSeedTwoModelAsserts(c *C) {
 	st := s.overlord.State()
 	st.Lock()
@@ -938,6 +3573,42 @@ func (s *FirstBootTestSuite) TestImportAssertionsFromSeedTwoModelAsserts(c *C) {
 	c.Assert(err, ErrorMatches, "cannot add more than one model assertion")
 }
 
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedIdenticalModelAsserts(c *C) {
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// write out the model assertion chain twice
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model", assertsChain)
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model-copy", assertsChain)
+
+	// identical copies of the model are fine
+	model, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, IsNil)
+	c.Check(model.Model(), Equals, "my-model")
+
+	var seedAssertions []string
+	err = st.Get("seed-assertions", &seedAssertions)
+	c.Assert(err, IsNil)
+	c.Check(seedAssertions, HasLen, len(assertsChain))
+}
+
+func (s *FirstBootTestSuite) TestImportAssertionsFromSeedDifferingModelAsserts(c *C) {
+	st := s.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// write out two different versions of the same model
+	assertsChain := s.makeModelAssertionChain(c, "my-model")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model", assertsChain)
+	model2 := s.makeModelAssertion(c, "my-model", "foo")
+	devicestatetest.WriteAssertions(c, dirs.SnapSeedDir, "model2", []asserts.Assertion{model2})
+
+	_, err := devicestate.ImportAssertionsFromSeed(st)
+	c.Assert(err, ErrorMatches, "cannot add more than one model assertion")
+}
+
 func (s *FirstBootTestSuite) TestImportAssertionsFromSeedNoModelAsserts(c *C) {
 	st := s.overlord.State()
 	st.Lock()
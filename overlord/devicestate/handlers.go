@@ -46,6 +46,7 @@ func (m *DeviceManager) doMarkSeeded(t *state.Task, _ *tomb.Tomb) error {
 	defer st.Unlock()
 
 	st.Set("seeded", true)
+	st.Set("seed-time", time.Now())
 	return nil
 }
 
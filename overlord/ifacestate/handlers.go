@@ -203,7 +203,7 @@ func (m *InterfaceManager) removeProfilesForSnap(task *state.Task, _ *tomb.Tomb,
 	// This is required to remove the snap from the interface repository.
 	// The returned list of affected snaps will need to have its security setup
 	// to reflect the change.
-	affectedSnaps, err := m.repo.DisconnectSnap(snapName)
+	affectedSnaps, err := m.disconnectSnap(task, snapName)
 	if err != nil {
 		return err
 	}
@@ -458,6 +458,16 @@ func (m *InterfaceManager) doDisconnect(task *state.Task, _ *tomb.Tomb) error {
 		}
 	}
 
+	// let the interface clean up before changing anything, so that
+	// on failure the connection is left intact
+	plug := m.repo.Plug(plugRef.Snap, plugRef.Name)
+	slot := m.repo.Slot(slotRef.Snap, slotRef.Name)
+	if plug != nil && slot != nil {
+		if err := m.cleanUpConnection(plug, slot); err != nil {
+			return fmt.Errorf("cannot clean up after disconnecting %s from %s: %v", plugRef, slotRef, err)
+		}
+	}
+
 	err = m.repo.Disconnect(plugRef.Snap, plugRef.Name, slotRef.Snap, slotRef.Name)
 	if err != nil {
 		return fmt.Errorf("snapd changed, please retry the operation: %v", err)
@@ -209,6 +209,64 @@ func (m *InterfaceManager) reloadConnections(snapName string) error {
 	return nil
 }
 
+// cleanUpConnection lets the interface of the connection between
+// plug and slot clean up after it gets disconnected.
+func (m *InterfaceManager) cleanUpConnection(plug *interfaces.Plug, slot *interfaces.Slot) error {
+	iface := m.repo.Interface(plug.Interface)
+	if disconnecter, ok := iface.(interfaces.PlugDisconnecter); ok {
+		if err := disconnecter.DisconnectPlug(plug, slot); err != nil {
+			return err
+		}
+	}
+	if disconnecter, ok := iface.(interfaces.SlotDisconnecter); ok {
+		if err := disconnecter.DisconnectSlot(plug, slot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// disconnectSnap disconnects all the plugs and slots of the snap like
+// the repository DisconnectSnap, letting the interfaces clean up after
+// each of the dropped connections. Problems cleaning up are only
+// logged as the snap is going away.
+func (m *InterfaceManager) disconnectSnap(task *state.Task, snapName string) ([]string, error) {
+	type connection struct {
+		plug *interfaces.Plug
+		slot *interfaces.Slot
+	}
+	var dropped []connection
+	for _, plug := range m.repo.Plugs(snapName) {
+		for _, slotRef := range plug.Connections {
+			if slot := m.repo.Slot(slotRef.Snap, slotRef.Name); slot != nil {
+				dropped = append(dropped, connection{plug, slot})
+			}
+		}
+	}
+	for _, slot := range m.repo.Slots(snapName) {
+		for _, plugRef := range slot.Connections {
+			if plugRef.Snap == snapName {
+				// seen already from the side of the plug
+				continue
+			}
+			if plug := m.repo.Plug(plugRef.Snap, plugRef.Name); plug != nil {
+				dropped = append(dropped, connection{plug, slot})
+			}
+		}
+	}
+
+	affectedSnaps, err := m.repo.DisconnectSnap(snapName)
+	if err != nil {
+		return nil, err
+	}
+	for _, conn := range dropped {
+		if err := m.cleanUpConnection(conn.plug, conn.slot); err != nil {
+			task.Logf("cannot clean up after disconnecting %s from %s: %v", conn.plug.Ref(), conn.slot.Ref(), err)
+		}
+	}
+	return affectedSnaps, nil
+}
+
 func (m *InterfaceManager) setupSnapSecurity(task *state.Task, snapInfo *snap.Info, opts interfaces.ConfinementOptions) error {
 	st := task.State()
 	snapName := snapInfo.Name()
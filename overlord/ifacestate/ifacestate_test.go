@@ -1481,6 +1481,93 @@ func (s *interfaceManagerSuite) TestDoRemove(c *C) {
 	})
 }
 
+func (s *interfaceManagerSuite) TestDoRemoveCleansUp(c *C) {
+	var calls []string
+	s.mockIface(c, &ifacetest.TestInterface{
+		InterfaceName: "test",
+		DisconnectPlugCallback: func(plug *interfaces.Plug, slot *interfaces.Slot) error {
+			calls = append(calls, "plug "+plug.Ref().String()+" "+slot.Ref().String())
+			return nil
+		},
+		DisconnectSlotCallback: func(plug *interfaces.Plug, slot *interfaces.Slot) error {
+			calls = append(calls, "slot "+plug.Ref().String()+" "+slot.Ref().String())
+			// problems cleaning up don't prevent the removal
+			return fmt.Errorf("cannot remove test file")
+		},
+	})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+
+	// Run the remove-security task
+	change := s.addRemoveSnapSecurityChange(c, "producer")
+	mgr.Ensure()
+	mgr.Wait()
+	mgr.Stop()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(change.Status(), Equals, state.DoneStatus)
+	c.Check(calls, DeepEquals, []string{
+		"plug consumer:plug producer:slot",
+		"slot consumer:plug producer:slot",
+	})
+	c.Check(strings.Join(change.Tasks()[0].Log(), "\n"), Matches, `.* cannot clean up after disconnecting consumer:plug from producer:slot: cannot remove test file`)
+	c.Check(mgr.Repository().Slot("producer", "slot"), IsNil)
+}
+
+func (s *interfaceManagerSuite) TestDoSetupProfilesKeepsConnectionsWithoutCleanUp(c *C) {
+	var calls []string
+	s.mockIface(c, &ifacetest.TestInterface{
+		InterfaceName: "test",
+		DisconnectPlugCallback: func(plug *interfaces.Plug, slot *interfaces.Slot) error {
+			calls = append(calls, "plug "+plug.Ref().String()+" "+slot.Ref().String())
+			return nil
+		},
+		DisconnectSlotCallback: func(plug *interfaces.Plug, slot *interfaces.Slot) error {
+			calls = append(calls, "slot "+plug.Ref().String()+" "+slot.Ref().String())
+			return nil
+		},
+	})
+	s.mockSnap(c, consumerYaml)
+	snapInfo := s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+
+	// Run the setup-profiles task as done when refreshing the producer
+	change := s.addSetupSnapSecurityChange(c, &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: snapInfo.Name(),
+			Revision: snapInfo.Revision,
+		},
+	})
+	mgr.Ensure()
+	mgr.Wait()
+	mgr.Stop()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(change.Status(), Equals, state.DoneStatus)
+	// the connection is kept so the interface doesn't clean up after it
+	c.Check(calls, HasLen, 0)
+	slot := mgr.Repository().Slot("producer", "slot")
+	c.Assert(slot, NotNil)
+	c.Check(slot.Connections, HasLen, 1)
+}
+
 func (s *interfaceManagerSuite) TestConnectTracksConnectionsInState(c *C) {
 	s.mockIface(c, &ifacetest.TestInterface{InterfaceName: "test"})
 	s.mockSnap(c, consumerYaml)
@@ -1602,6 +1689,114 @@ func (s *interfaceManagerSuite) TestDisconnectSetsUpSecurity(c *C) {
 	c.Check(s.secBackend.SetupCalls[1].Options, Equals, interfaces.ConfinementOptions{})
 }
 
+func (s *interfaceManagerSuite) TestDisconnectCleansUp(c *C) {
+	var calls []string
+	s.mockIface(c, &ifacetest.TestInterface{
+		InterfaceName: "test",
+		DisconnectPlugCallback: func(plug *interfaces.Plug, slot *interfaces.Slot) error {
+			calls = append(calls, "plug "+plug.Ref().String()+" "+slot.Ref().String())
+			return nil
+		},
+		DisconnectSlotCallback: func(plug *interfaces.Plug, slot *interfaces.Slot) error {
+			calls = append(calls, "slot "+plug.Ref().String()+" "+slot.Ref().String())
+			return nil
+		},
+	})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	ts, err := ifacestate.Disconnect(s.state, "consumer", "plug", "producer", "slot")
+	c.Assert(err, IsNil)
+	ts.Tasks()[0].Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "consumer",
+		},
+	})
+
+	change := s.state.NewChange("disconnect", "")
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	mgr.Ensure()
+	mgr.Wait()
+	mgr.Stop()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Err(), IsNil)
+	c.Check(change.Status(), Equals, state.DoneStatus)
+	c.Check(calls, DeepEquals, []string{
+		"plug consumer:plug producer:slot",
+		"slot consumer:plug producer:slot",
+	})
+	var conns map[string]interface{}
+	err = s.state.Get("conns", &conns)
+	c.Assert(err, IsNil)
+	c.Check(conns, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestDisconnectCleanUpError(c *C) {
+	s.mockIface(c, &ifacetest.TestInterface{
+		InterfaceName: "test",
+		DisconnectSlotCallback: func(plug *interfaces.Plug, slot *interfaces.Slot) error {
+			return fmt.Errorf("cannot remove test file")
+		},
+	})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	ts, err := ifacestate.Disconnect(s.state, "consumer", "plug", "producer", "slot")
+	c.Assert(err, IsNil)
+	ts.Tasks()[0].Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "consumer",
+		},
+	})
+
+	change := s.state.NewChange("disconnect", "")
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	mgr.Ensure()
+	mgr.Wait()
+	mgr.Stop()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Check(change.Err(), ErrorMatches, `(?s).*cannot clean up after disconnecting consumer:plug from producer:slot: cannot remove test file.*`)
+	c.Check(change.Status(), Equals, state.ErrorStatus)
+
+	// nothing was changed
+	conns, err := mgr.Repository().Connected("consumer", "plug")
+	c.Assert(err, IsNil)
+	c.Check(conns, HasLen, 1)
+	c.Check(s.secBackend.SetupCalls, HasLen, 0)
+	var connsState map[string]interface{}
+	err = s.state.Get("conns", &connsState)
+	c.Assert(err, IsNil)
+	c.Check(connsState, HasLen, 1)
+}
+
 func (s *interfaceManagerSuite) TestDisconnectTracksConnectionsInState(c *C) {
 	s.mockIface(c, &ifacetest.TestInterface{InterfaceName: "test"})
 	s.mockSnap(c, consumerYaml)
@@ -58,11 +58,21 @@ func updateCurrentSymlinks(info *snap.Info) error {
 
 // LinkSnap makes the snap available by generating wrappers and setting the current symlinks.
 func (b Backend) LinkSnap(info *snap.Info) error {
+	return linkSnap(info, true)
+}
+
+// LinkSnapWithDisabledServices is like LinkSnap but leaves the
+// services of the snap disabled.
+func (b Backend) LinkSnapWithDisabledServices(info *snap.Info) error {
+	return linkSnap(info, false)
+}
+
+func linkSnap(info *snap.Info, enableServices bool) error {
 	if info.Revision.Unset() {
 		return fmt.Errorf("cannot link snap %q with unset revision", info.Name())
 	}
 
-	if err := generateWrappers(info); err != nil {
+	if err := generateWrappers(info, enableServices); err != nil {
 		return err
 	}
 
@@ -82,13 +92,17 @@ func (b Backend) StopServices(apps []*snap.AppInfo, meter progress.Meter) error
 	return wrappers.StopServices(apps, meter)
 }
 
-func generateWrappers(s *snap.Info) error {
+func generateWrappers(s *snap.Info, enableServices bool) error {
 	// add the CLI apps from the snap.yaml
 	if err := wrappers.AddSnapBinaries(s); err != nil {
 		return err
 	}
 	// add the daemons from the snap.yaml
-	if err := wrappers.AddSnapServices(s, &progress.NullProgress{}); err != nil {
+	addSnapServices := wrappers.AddSnapServices
+	if !enableServices {
+		addSnapServices = wrappers.AddDisabledSnapServices
+	}
+	if err := addSnapServices(s, &progress.NullProgress{}); err != nil {
 		wrappers.RemoveSnapBinaries(s)
 		return err
 	}
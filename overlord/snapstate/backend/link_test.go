@@ -33,6 +33,7 @@ import (
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
 
 	"github.com/snapcore/snapd/overlord/snapstate/backend"
 )
@@ -214,6 +215,39 @@ apps:
 	c.Check(osutil.FileExists(currentDataSymlink), Equals, false)
 }
 
+func (s *linkSuite) TestLinkSnapWithDisabledServices(c *C) {
+	var sysdLog [][]string
+	r := systemd.MockSystemctl(func(cmd ...string) ([]byte, error) {
+		sysdLog = append(sysdLog, cmd)
+		return []byte("ActiveState=inactive\n"), nil
+	})
+	defer r()
+
+	const yaml = `name: hello
+version: 1.0
+apps:
+ svc:
+   command: svc
+   daemon: simple
+`
+	info := snaptest.MockSnap(c, yaml, "", &snap.SideInfo{Revision: snap.R(11)})
+
+	err := s.be.LinkSnapWithDisabledServices(info)
+	c.Assert(err, IsNil)
+
+	// the service unit is written but not enabled
+	l, err := filepath.Glob(filepath.Join(dirs.SnapServicesDir, "*.service"))
+	c.Assert(err, IsNil)
+	c.Check(l, HasLen, 1)
+	for _, cmd := range sysdLog {
+		c.Check(cmd, Not(testutil.Contains), "enable")
+	}
+
+	// and the snap is current
+	currentActiveSymlink := filepath.Join(info.MountDir(), "..", "current")
+	c.Check(osutil.IsSymlink(currentActiveSymlink), Equals, true)
+}
+
 func (s *linkSuite) TestLinkFailsForUnsetRevision(c *C) {
 	info := &snap.Info{
 		SuggestedName: "foo",
@@ -430,6 +430,14 @@ func (f *fakeSnappyBackend) LinkSnap(info *snap.Info) error {
 	return nil
 }
 
+func (f *fakeSnappyBackend) LinkSnapWithDisabledServices(info *snap.Info) error {
+	f.ops = append(f.ops, fakeOp{
+		op:   "link-snap-disabled-services",
+		name: info.MountDir(),
+	})
+	return nil
+}
+
 func svcSnapMountDir(svcs []*snap.AppInfo) string {
 	if len(svcs) == 0 {
 		return "<no services>"
@@ -50,6 +50,11 @@ type Flags struct {
 	// running the configure hook should be skipped.
 	SkipConfigure bool `json:"skip-configure,omitempty"`
 
+	// SkipStartServices is used with InstallPath to flag that the
+	// services of the snap should be neither enabled nor started, they
+	// can then be enabled and started with "snap start --enable".
+	SkipStartServices bool `json:"skip-start-services,omitempty"`
+
 	// Unaliased is set to request that no automatic aliases are created
 	// installing the snap.
 	Unaliased bool `json:"unaliased,omitempty"`
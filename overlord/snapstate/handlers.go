@@ -155,9 +155,9 @@ func (m *SnapManager) doPrerequisites(t *state.Task, _ *tomb.Tomb) error {
 		return err
 	}
 
-	// core/ubuntu-core can not have prerequisites
+	// core/ubuntu-core/snapd can not have prerequisites
 	snapName := snapsup.Name()
-	if snapName == defaultCoreSnapName || snapName == "ubuntu-core" {
+	if snapName == defaultCoreSnapName || snapName == "ubuntu-core" || snapName == "snapd" {
 		return nil
 	}
 
@@ -643,6 +643,10 @@ func (m *SnapManager) doLinkSnap(t *state.Task, _ *tomb.Tomb) error {
 	if snapsup.Channel != "" {
 		snapst.Channel = snapsup.Channel
 	}
+	oldCohortKey := snapst.CohortKey
+	if snapsup.CohortKey != "" {
+		snapst.CohortKey = snapsup.CohortKey
+	}
 	oldTryMode := snapst.TryMode
 	snapst.TryMode = snapsup.TryMode
 	oldDevMode := snapst.DevMode
@@ -663,8 +667,15 @@ func (m *SnapManager) doLinkSnap(t *state.Task, _ *tomb.Tomb) error {
 	// record type
 	snapst.SetType(newInfo.Type)
 
+	// services of snaps installed with SkipStartServices are left
+	// disabled, so that they also don't start on the next boot
+	linkSnap := m.backend.LinkSnap
+	if snapsup.SkipStartServices {
+		linkSnap = m.backend.LinkSnapWithDisabledServices
+	}
+
 	// XXX: this block is slightly ugly, find a pattern when we have more examples
-	err = m.backend.LinkSnap(newInfo)
+	err = linkSnap(newInfo)
 	if err != nil {
 		pb := NewTaskProgressAdapterLocked(t)
 		err := m.backend.UnlinkSnap(newInfo, pb)
@@ -695,6 +706,7 @@ func (m *SnapManager) doLinkSnap(t *state.Task, _ *tomb.Tomb) error {
 	t.Set("old-jailmode", oldJailMode)
 	t.Set("old-classic", oldClassic)
 	t.Set("old-channel", oldChannel)
+	t.Set("old-cohort-key", oldCohortKey)
 	t.Set("old-current", oldCurrent)
 	t.Set("old-candidate-index", oldCandidateIndex)
 	// Do at the end so we only preserve the new state if it worked.
@@ -738,6 +750,12 @@ func (m *SnapManager) undoLinkSnap(t *state.Task, _ *tomb.Tomb) error {
 	if err != nil {
 		return err
 	}
+	// not set by link-snap tasks from before cohorts were tracked
+	var oldCohortKey string
+	err = t.Get("old-cohort-key", &oldCohortKey)
+	if err != nil && err != state.ErrNoState {
+		return err
+	}
 	var oldTryMode bool
 	err = t.Get("old-trymode", &oldTryMode)
 	if err != nil {
@@ -792,6 +810,7 @@ func (m *SnapManager) undoLinkSnap(t *state.Task, _ *tomb.Tomb) error {
 	snapst.Current = oldCurrent
 	snapst.Active = false
 	snapst.Channel = oldChannel
+	snapst.CohortKey = oldCohortKey
 	snapst.TryMode = oldTryMode
 	snapst.DevMode = oldDevMode
 	snapst.JailMode = oldJailMode
@@ -93,6 +93,9 @@ type SnapSetup struct {
 	Channel string `json:"channel,omitempty"`
 	UserID  int    `json:"user-id,omitempty"`
 	Base    string `json:"base,omitempty"`
+	// CohortKey is the key of the cohort of the channel the snap
+	// should keep to, if any
+	CohortKey string `json:"cohort-key,omitempty"`
 
 	Flags
 
@@ -135,6 +138,9 @@ type SnapState struct {
 	// (usually while a snap is being operated on or disabled)
 	Current snap.Revision `json:"current"`
 	Channel string        `json:"channel,omitempty"`
+	// CohortKey is the key of the cohort of the channel the snap
+	// keeps to, if any
+	CohortKey string `json:"cohort-key,omitempty"`
 	Flags
 	// aliases, see aliasesv2.go
 	Aliases             map[string]*AliasTarget `json:"aliases,omitempty"`
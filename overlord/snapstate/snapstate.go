@@ -48,6 +48,7 @@ import (
 const (
 	maybeCore = 1 << iota
 	skipConfigure
+	skipStartServices
 )
 
 // control flags for "Configure()"
@@ -197,9 +198,11 @@ func doInstall(st *state.State, snapst *SnapState, snapsup *SnapSetup, flags int
 	}
 
 	// run new serices
-	startSnapServices := st.NewTask("start-snap-services", fmt.Sprintf(i18n.G("Start snap %q%s services"), snapsup.Name(), revisionStr))
-	addTask(startSnapServices)
-	prev = startSnapServices
+	if flags&skipStartServices == 0 {
+		startSnapServices := st.NewTask("start-snap-services", fmt.Sprintf(i18n.G("Start snap %q%s services"), snapsup.Name(), revisionStr))
+		addTask(startSnapServices)
+		prev = startSnapServices
+	}
 
 	// Do not do that if we are reverting to a local revision
 	if snapst.IsInstalled() && !snapsup.Flags.Revert {
@@ -432,6 +435,13 @@ func CheckChangeConflict(st *state.State, snapName string, checkConflictPredicat
 // local revision and sideloading, or full metadata in which case it
 // the snap will appear as installed from the store.
 func InstallPath(st *state.State, si *snap.SideInfo, path, channel string, flags Flags) (*state.TaskSet, error) {
+	return InstallPathWithCohort(st, si, path, channel, "", flags)
+}
+
+// InstallPathWithCohort is like InstallPath but also records the key
+// of the cohort of the channel the snap should keep to.
+// Note that the state must be locked by the caller.
+func InstallPathWithCohort(st *state.State, si *snap.SideInfo, path, channel, cohortKey string, flags Flags) (*state.TaskSet, error) {
 	name := si.RealName
 	if name == "" {
 		return nil, fmt.Errorf("internal error: snap name to install %q not provided", path)
@@ -455,6 +465,9 @@ func InstallPath(st *state.State, si *snap.SideInfo, path, channel string, flags
 		// into SnapSetup
 		instFlags |= skipConfigure
 	}
+	if flags.SkipStartServices {
+		instFlags |= skipStartServices
+	}
 
 	// It is ok do open the snap file here because we either
 	// have side info or the user passed --dangerous
@@ -464,11 +477,12 @@ func InstallPath(st *state.State, si *snap.SideInfo, path, channel string, flags
 	}
 
 	snapsup := &SnapSetup{
-		Base:     info.Base,
-		SideInfo: si,
-		SnapPath: path,
-		Channel:  channel,
-		Flags:    flags.ForSnapSetup(),
+		Base:      info.Base,
+		SideInfo:  si,
+		SnapPath:  path,
+		Channel:   channel,
+		CohortKey: cohortKey,
+		Flags:     flags.ForSnapSetup(),
 	}
 
 	return doInstall(st, &snapst, snapsup, instFlags)
@@ -589,10 +603,11 @@ func refreshCandidates(st *state.State, names []string, user *auth.UserState) ([
 		// get confinement preference from the snapstate
 		candidateInfo := &store.RefreshCandidate{
 			// the desired channel (not info.Channel!)
-			Channel:  snapst.Channel,
-			SnapID:   snapInfo.SnapID,
-			Revision: snapInfo.Revision,
-			Epoch:    snapInfo.Epoch,
+			Channel:   snapst.Channel,
+			CohortKey: snapst.CohortKey,
+			SnapID:    snapInfo.SnapID,
+			Revision:  snapInfo.Revision,
+			Epoch:     snapInfo.Epoch,
 		}
 
 		if len(names) == 0 {
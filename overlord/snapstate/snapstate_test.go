@@ -3099,6 +3099,163 @@ version: 1.0`)
 	c.Assert(snapst.Required, Equals, true)
 }
 
+func (s *snapmgrTestSuite) TestInstallPathWithCohortRunThrough(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	someSnap := makeTestSnap(c, `name: some-snap
+version: 1.0`)
+	chg := s.state.NewChange("install", "install a local snap")
+
+	si := &snap.SideInfo{
+		RealName: "some-snap",
+		SnapID:   "snapIDsnapidsnapidsnapidsnapidsn",
+		Revision: snap.R(42),
+	}
+	ts, err := snapstate.InstallPathWithCohort(s.state, si, someSnap, "stable", "some-cohort-key", snapstate.Flags{})
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	snapsup, err := snapstate.TaskSnapSetup(ts.Tasks()[0])
+	c.Assert(err, IsNil)
+	c.Check(snapsup.CohortKey, Equals, "some-cohort-key")
+
+	s.state.Unlock()
+	defer s.snapmgr.Stop()
+	s.settle(c)
+	s.state.Lock()
+
+	c.Assert(chg.Err(), IsNil)
+
+	var snapst snapstate.SnapState
+	err = snapstate.Get(s.state, "some-snap", &snapst)
+	c.Assert(err, IsNil)
+	c.Check(snapst.Channel, Equals, "stable")
+	c.Check(snapst.CohortKey, Equals, "some-cohort-key")
+}
+
+func (s *snapmgrTestSuite) TestInstallPathWithCohortUndoRunThrough(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	si := snap.SideInfo{
+		RealName: "some-snap",
+		SnapID:   "some-snap-id",
+		Revision: snap.R(7),
+	}
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:    true,
+		Sequence:  []*snap.SideInfo{&si},
+		Channel:   "stable",
+		CohortKey: "old-cohort-key",
+		Current:   si.Revision,
+		SnapType:  "app",
+	})
+
+	someSnap := makeTestSnap(c, `name: some-snap
+version: 1.0`)
+	chg := s.state.NewChange("install", "install a local snap")
+
+	newSi := &snap.SideInfo{
+		RealName: "some-snap",
+		SnapID:   "some-snap-id",
+		Revision: snap.R(8),
+	}
+	ts, err := snapstate.InstallPathWithCohort(s.state, newSi, someSnap, "stable", "new-cohort-key", snapstate.Flags{})
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	tasks := ts.Tasks()
+	last := tasks[len(tasks)-1]
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitFor(last)
+	terr.JoinLane(last.Lanes()[0])
+	chg.AddTask(terr)
+
+	s.state.Unlock()
+	defer s.snapmgr.Stop()
+	s.settle(c)
+	s.state.Lock()
+
+	c.Assert(chg.Status(), Equals, state.ErrorStatus)
+
+	var snapst snapstate.SnapState
+	err = snapstate.Get(s.state, "some-snap", &snapst)
+	c.Assert(err, IsNil)
+	c.Check(snapst.Current, Equals, snap.R(7))
+	c.Check(snapst.CohortKey, Equals, "old-cohort-key")
+}
+
+func (s *snapmgrTestSuite) TestUpdateSendsCohortKey(c *C) {
+	si := snap.SideInfo{
+		RealName: "some-snap",
+		SnapID:   "some-snap-id",
+		Revision: snap.R(7),
+	}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:    true,
+		Sequence:  []*snap.SideInfo{&si},
+		Channel:   "stable",
+		CohortKey: "some-cohort-key",
+		Current:   si.Revision,
+		SnapType:  "app",
+	})
+
+	_, err := snapstate.Update(s.state, "some-snap", "", snap.R(0), s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	_, _, err = snapstate.UpdateMany(s.state, []string{"some-snap"}, s.user.ID)
+	c.Assert(err, IsNil)
+
+	// both the single and the multi refresh ask for the cohort
+	c.Assert(s.fakeBackend.ops, HasLen, 2)
+	for _, op := range s.fakeBackend.ops {
+		c.Check(op.op, Equals, "storesvc-list-refresh")
+		c.Check(op.cand.CohortKey, Equals, "some-cohort-key")
+	}
+}
+
+func (s *snapmgrTestSuite) TestUpdateKeepsCohortKeyRunThrough(c *C) {
+	si := snap.SideInfo{
+		RealName: "services-snap",
+		SnapID:   "services-snap-id",
+		Revision: snap.R(7),
+	}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "services-snap", &snapstate.SnapState{
+		Active:    true,
+		Sequence:  []*snap.SideInfo{&si},
+		Channel:   "stable",
+		CohortKey: "some-cohort-key",
+		Current:   si.Revision,
+		SnapType:  "app",
+	})
+
+	chg := s.state.NewChange("refresh", "refresh a snap")
+	ts, err := snapstate.Update(s.state, "services-snap", "", snap.R(0), s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	s.state.Unlock()
+	defer s.snapmgr.Stop()
+	s.settle(c)
+	s.state.Lock()
+
+	c.Assert(chg.Err(), IsNil)
+
+	var snapst snapstate.SnapState
+	err = snapstate.Get(s.state, "services-snap", &snapst)
+	c.Assert(err, IsNil)
+	c.Check(snapst.Current, Equals, snap.R(11))
+	c.Check(snapst.CohortKey, Equals, "some-cohort-key")
+}
+
 func (s *snapmgrTestSuite) TestRemoveRunThrough(c *C) {
 	si := snap.SideInfo{
 		RealName: "some-snap",
@@ -6259,6 +6416,59 @@ func (s *snapmgrTestSuite) TestInstallPathSkipConfigure(c *C) {
 	c.Check(snapsup.Flags.SkipConfigure, Equals, false)
 }
 
+func (s *snapmgrTestSuite) TestInstallPathSkipStartServices(c *C) {
+	r := release.MockOnClassic(false)
+	defer r()
+
+	makeInstalledMockCoreSnap(c)
+
+	// using MockSnap, we want to read the bits on disk
+	snapstate.MockReadInfo(snap.ReadInfo)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.prepareGadget(c)
+
+	snapPath := makeTestSnap(c, "name: some-snap\nversion: 1.0\napps:\n svc:\n  daemon: simple")
+
+	ts, err := snapstate.InstallPath(s.state, &snap.SideInfo{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(1)}, snapPath, "edge", snapstate.Flags{SkipStartServices: true})
+	c.Assert(err, IsNil)
+
+	for _, t := range ts.Tasks() {
+		c.Check(t.Kind(), Not(Equals), "start-snap-services")
+	}
+
+	snapsup, err := snapstate.TaskSnapSetup(ts.Tasks()[0])
+	c.Assert(err, IsNil)
+	// link-snap needs SkipStartServices to leave the services disabled
+	c.Check(snapsup.Flags.SkipStartServices, Equals, true)
+}
+
+func (s *snapmgrTestSuite) TestInstallPathSkipStartServicesRunThrough(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	someSnap := makeTestSnap(c, "name: some-snap\nversion: 1.0\napps:\n svc:\n  daemon: simple")
+	chg := s.state.NewChange("install", "install a local snap")
+	ts, err := snapstate.InstallPath(s.state, &snap.SideInfo{RealName: "some-snap"}, someSnap, "", snapstate.Flags{SkipStartServices: true})
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	s.state.Unlock()
+	defer s.snapmgr.Stop()
+	s.settle(c)
+	s.state.Lock()
+
+	c.Assert(chg.Err(), IsNil)
+
+	// the services are neither enabled nor started
+	ops := s.fakeBackend.ops.Ops()
+	c.Check(ops, testutil.Contains, "link-snap-disabled-services")
+	c.Check(ops, Not(testutil.Contains), "link-snap")
+	c.Check(ops, Not(testutil.Contains), "start-snap-services")
+}
+
 func (s *snapmgrTestSuite) TestGadgetDefaultsInstalled(c *C) {
 	makeInstalledMockCoreSnap(c)
 
@@ -50,10 +50,11 @@ func updateInfo(st *state.State, snapst *SnapState, channel string, userID int)
 
 	refreshCand := &store.RefreshCandidate{
 		// the desired channel
-		Channel:  channel,
-		SnapID:   curInfo.SnapID,
-		Revision: curInfo.Revision,
-		Epoch:    curInfo.Epoch,
+		Channel:   channel,
+		CohortKey: snapst.CohortKey,
+		SnapID:    curInfo.SnapID,
+		Revision:  curInfo.Revision,
+		Epoch:     curInfo.Epoch,
 	}
 
 	theStore := storestate.Store(st)
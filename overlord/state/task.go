@@ -173,6 +173,12 @@ func (t *Task) Summary() string {
 	return t.summary
 }
 
+// SetSummary replaces the summary describing what the task is about.
+func (t *Task) SetSummary(summary string) {
+	t.state.writing()
+	t.summary = summary
+}
+
 // Status returns the current task status.
 func (t *Task) Status() Status {
 	t.state.reading()
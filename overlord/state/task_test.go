@@ -45,6 +45,17 @@ func (ts *taskSuite) TestNewTask(c *C) {
 	c.Check(t.Summary(), Equals, "1...")
 }
 
+func (ts *taskSuite) TestSetSummary(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	t := st.NewTask("download", "1...")
+	t.SetSummary("2...")
+
+	c.Check(t.Summary(), Equals, "2...")
+}
+
 func (cs *taskSuite) TestReadyTime(c *C) {
 	st := state.New(nil)
 	st.Lock()
@@ -79,18 +79,42 @@ func ReadGadgetInfo(info *Info, classic bool) (*GadgetInfo, error) {
 		return nil, fmt.Errorf(errorFormat, "not a gadget snap")
 	}
 
-	var gi GadgetInfo
-
 	gadgetYamlFn := filepath.Join(info.MountDir(), "meta", "gadget.yaml")
 	gmeta, err := ioutil.ReadFile(gadgetYamlFn)
 	if classic && os.IsNotExist(err) {
 		// gadget.yaml is optional for classic gadgets
-		return &gi, nil
+		return &GadgetInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(errorFormat, err)
+	}
+
+	return parseGadgetInfo(gmeta, classic)
+}
+
+// ReadGadgetInfoFromSnapFile reads the gadget specific metadata from
+// gadget.yaml in the given gadget snap file, following the same rules
+// as ReadGadgetInfo.
+func ReadGadgetInfoFromSnapFile(snapf Container, classic bool) (*GadgetInfo, error) {
+	const errorFormat = "cannot read gadget snap details: %s"
+
+	gmeta, err := snapf.ReadFile("meta/gadget.yaml")
+	if classic && os.IsNotExist(err) {
+		// gadget.yaml is optional for classic gadgets
+		return &GadgetInfo{}, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf(errorFormat, err)
 	}
 
+	return parseGadgetInfo(gmeta, classic)
+}
+
+func parseGadgetInfo(gmeta []byte, classic bool) (*GadgetInfo, error) {
+	const errorFormat = "cannot read gadget snap details: %s"
+
+	var gi GadgetInfo
+
 	if err := yaml.Unmarshal(gmeta, &gi); err != nil {
 		return nil, fmt.Errorf(errorFormat, err)
 	}
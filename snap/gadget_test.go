@@ -180,6 +180,30 @@ func (s *gadgetYamlTestSuite) TestReadGadgetYamlValid(c *C) {
 	})
 }
 
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlFromSnapFile(c *C) {
+	snapPath := snaptest.MakeTestSnapWithFiles(c, mockGadgetSnapYaml, [][]string{
+		{"meta/gadget.yaml", string(mockGadgetYaml)},
+	})
+	snapf, err := snap.Open(snapPath)
+	c.Assert(err, IsNil)
+
+	ginfo, err := snap.ReadGadgetInfoFromSnapFile(snapf, false)
+	c.Assert(err, IsNil)
+	c.Check(ginfo.Defaults, DeepEquals, map[string]map[string]interface{}{
+		"core": {"something": true},
+	})
+	c.Check(ginfo.Volumes["volumename"].Bootloader, Equals, "u-boot")
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlFromSnapFileMissing(c *C) {
+	snapPath := snaptest.MakeTestSnapWithFiles(c, mockGadgetSnapYaml, nil)
+	snapf, err := snap.Open(snapPath)
+	c.Assert(err, IsNil)
+
+	_, err = snap.ReadGadgetInfoFromSnapFile(snapf, false)
+	c.Assert(err, ErrorMatches, "cannot read gadget snap details: .*")
+}
+
 func (s *gadgetYamlTestSuite) TestReadGadgetYamlEmptydBootloader(c *C) {
 	info := snaptest.MockSnap(c, mockGadgetSnapYaml, mockGadgetSnapContents, &snap.SideInfo{Revision: snap.R(42)})
 	mockGadgetYamlBroken := []byte(`
@@ -22,6 +22,8 @@ package snap
 import (
 	"fmt"
 	"io/ioutil"
+	"reflect"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v2"
@@ -40,6 +42,7 @@ type SeedSnap struct {
 
 	// bits that are orthongonal/not in assertions
 	Channel string `yaml:"channel,omitempty"`
+	Cohort  string `yaml:"cohort,omitempty"`
 	DevMode bool   `yaml:"devmode,omitempty"`
 	Classic bool   `yaml:"classic,omitempty"`
 
@@ -47,9 +50,22 @@ type SeedSnap struct {
 
 	Contact string `yaml:"contact,omitempty"`
 
+	// install the snap but hold back configuring it and starting its
+	// services, for that to be done later with "snap set" and
+	// "snap start"; essential snaps cannot be held
+	Hold bool `yaml:"hold,omitempty"`
+
+	// prefer the content this snap provides over the same content
+	// from other seed snaps when connecting content plugs
+	Prefer bool `yaml:"prefer,omitempty"`
+
 	// no assertions are available in the seed for this snap
 	Unasserted bool `yaml:"unasserted,omitempty"`
 
+	// install the snap in try mode, File is then an unpacked snap
+	// directory instead of a snap file
+	TryMode bool `yaml:"try,omitempty"`
+
 	File string `yaml:"file"`
 }
 
@@ -57,6 +73,64 @@ type Seed struct {
 	Snaps []*SeedSnap `yaml:"snaps"`
 }
 
+// seedFields and seedSnapFields are the fields seed.yaml and its
+// snap entries can have
+var (
+	seedFields     = yamlFields(reflect.TypeOf(Seed{}))
+	seedSnapFields = yamlFields(reflect.TypeOf(SeedSnap{}))
+)
+
+// yamlFields returns the names of the yaml fields of the struct type t.
+func yamlFields(t reflect.Type) map[string]bool {
+	fields := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// checkSeedYamlFields checks that seed.yaml uses only known fields,
+// so that typos don't go unnoticed.
+func checkSeedYamlFields(yamlData []byte) error {
+	var top map[string]interface{}
+	if err := yaml.Unmarshal(yamlData, &top); err != nil {
+		return err
+	}
+	var raw struct {
+		Snaps []map[string]interface{} `yaml:"snaps"`
+	}
+	if err := yaml.Unmarshal(yamlData, &raw); err != nil {
+		return err
+	}
+
+	if err := checkKnownFields(top, seedFields); err != nil {
+		return err
+	}
+	for _, sn := range raw.Snaps {
+		if err := checkKnownFields(sn, seedSnapFields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkKnownFields(entry map[string]interface{}, known map[string]bool) error {
+	var unknown []string
+	for field := range entry {
+		if !known[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("cannot read seed.yaml: unknown field %q", unknown[0])
+}
+
 func ReadSeedYaml(fn string) (*Seed, error) {
 	yamlData, err := ioutil.ReadFile(fn)
 	if err != nil {
@@ -67,12 +141,23 @@ func ReadSeedYaml(fn string) (*Seed, error) {
 	if err := yaml.Unmarshal(yamlData, &seed); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal %q: %s", yamlData, err)
 	}
+	if err := checkSeedYamlFields(yamlData); err != nil {
+		return nil, err
+	}
 
 	// validate
+	seen := make(map[string]bool, len(seed.Snaps))
 	for _, sn := range seed.Snaps {
 		if strings.Contains(sn.File, "/") {
 			return nil, fmt.Errorf("%q must be a filename, not a path", sn.File)
 		}
+		if sn.TryMode && !sn.Unasserted {
+			return nil, fmt.Errorf("seed.yaml entry for snap %q in try mode must be unasserted", sn.Name)
+		}
+		if seen[sn.Name] {
+			return nil, fmt.Errorf("seed.yaml contains duplicate entry for snap %q", sn.Name)
+		}
+		seen[sn.Name] = true
 	}
 
 	return &seed, nil
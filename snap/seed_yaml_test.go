@@ -37,10 +37,13 @@ snaps:
  - name: foo
    snap-id: snapidsnapidsnapid
    channel: stable
+   cohort: some-cohort-key
    devmode: true
+   prefer: true
    file: foo_1.0_all.snap
  - name: local
    unasserted: true
+   hold: true
    file: local.snap
 `)
 
@@ -58,12 +61,15 @@ func (s *seedYamlTestSuite) TestSimple(c *C) {
 		SnapID: "snapidsnapidsnapid",
 
 		Channel: "stable",
+		Cohort:  "some-cohort-key",
 		DevMode: true,
+		Prefer:  true,
 	})
 	c.Assert(seed.Snaps[1], DeepEquals, &snap.SeedSnap{
 		File:       "local.snap",
 		Name:       "local",
 		Unasserted: true,
+		Hold:       true,
 	})
 }
 
@@ -81,3 +87,83 @@ func (s *seedYamlTestSuite) TestNoPathAllowed(c *C) {
 	_, err = snap.ReadSeedYaml(fn)
 	c.Assert(err, ErrorMatches, `"foo/bar.snap" must be a filename, not a path`)
 }
+
+var duplicatedMockSeedYaml = []byte(`
+snaps:
+ - name: foo
+   file: foo_1.0_all.snap
+ - name: foo
+   file: foo_2.0_all.snap
+`)
+
+func (s *seedYamlTestSuite) TestDuplicatedSnapName(c *C) {
+	fn := filepath.Join(c.MkDir(), "seed.yaml")
+	err := ioutil.WriteFile(fn, duplicatedMockSeedYaml, 0644)
+	c.Assert(err, IsNil)
+
+	_, err = snap.ReadSeedYaml(fn)
+	c.Assert(err, ErrorMatches, `seed.yaml contains duplicate entry for snap "foo"`)
+}
+
+func (s *seedYamlTestSuite) TestTryMode(c *C) {
+	fn := filepath.Join(c.MkDir(), "seed.yaml")
+	err := ioutil.WriteFile(fn, []byte(`
+snaps:
+ - name: local
+   unasserted: true
+   try: true
+   file: local
+`), 0644)
+	c.Assert(err, IsNil)
+
+	seed, err := snap.ReadSeedYaml(fn)
+	c.Assert(err, IsNil)
+	c.Assert(seed.Snaps, HasLen, 1)
+	c.Check(seed.Snaps[0], DeepEquals, &snap.SeedSnap{
+		File:       "local",
+		Name:       "local",
+		Unasserted: true,
+		TryMode:    true,
+	})
+}
+
+func (s *seedYamlTestSuite) TestTryModeAsserted(c *C) {
+	fn := filepath.Join(c.MkDir(), "seed.yaml")
+	err := ioutil.WriteFile(fn, []byte(`
+snaps:
+ - name: foo
+   try: true
+   file: foo
+`), 0644)
+	c.Assert(err, IsNil)
+
+	_, err = snap.ReadSeedYaml(fn)
+	c.Assert(err, ErrorMatches, `seed.yaml entry for snap "foo" in try mode must be unasserted`)
+}
+
+func (s *seedYamlTestSuite) TestUnknownField(c *C) {
+	fn := filepath.Join(c.MkDir(), "seed.yaml")
+	err := ioutil.WriteFile(fn, []byte(`
+snaps:
+ - name: foo
+   chanel: edge
+   file: foo_1.0_all.snap
+`), 0644)
+	c.Assert(err, IsNil)
+
+	_, err = snap.ReadSeedYaml(fn)
+	c.Assert(err, ErrorMatches, `cannot read seed.yaml: unknown field "chanel"`)
+}
+
+func (s *seedYamlTestSuite) TestUnknownTopLevelField(c *C) {
+	fn := filepath.Join(c.MkDir(), "seed.yaml")
+	err := ioutil.WriteFile(fn, []byte(`
+snap:
+ - name: foo
+   file: foo_1.0_all.snap
+`), 0644)
+	c.Assert(err, IsNil)
+
+	_, err = snap.ReadSeedYaml(fn)
+	c.Assert(err, ErrorMatches, `cannot read seed.yaml: unknown field "snap"`)
+}
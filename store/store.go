@@ -1194,6 +1194,8 @@ type RefreshCandidate struct {
 
 	// the desired channel
 	Channel string
+	// the cohort of the channel to keep to, if any
+	CohortKey string
 }
 
 // the exact bits that we need to send to the store
@@ -1203,6 +1205,7 @@ type currentSnapJSON struct {
 	Revision    int    `json:"revision,omitempty"`
 	Epoch       string `json:"epoch"`
 	Confinement string `json:"confinement"`
+	CohortKey   string `json:"cohort_key,omitempty"`
 }
 
 type metadataWrapper struct {
@@ -1230,10 +1233,11 @@ func currentSnap(cs *RefreshCandidate) *currentSnapJSON {
 	}
 
 	return &currentSnapJSON{
-		SnapID:   cs.SnapID,
-		Channel:  channel,
-		Epoch:    cs.Epoch,
-		Revision: cs.Revision.N,
+		SnapID:    cs.SnapID,
+		Channel:   channel,
+		Epoch:     cs.Epoch,
+		Revision:  cs.Revision.N,
+		CohortKey: cs.CohortKey,
 		// confinement purposely left empty
 	}
 }
@@ -3087,6 +3087,36 @@ func (t *remoteRepoTestSuite) TestUbuntuStoreRepositoryLookupRefresh(c *C) {
 	c.Assert(result.Deltas, HasLen, 0)
 }
 
+func (t *remoteRepoTestSuite) TestUbuntuStoreRepositoryLookupRefreshCohortKey(c *C) {
+	defer mockRFC(func(_ *Store, currentSnaps []*currentSnapJSON, _ *auth.UserState) ([]*snapDetails, error) {
+		c.Check(currentSnaps, DeepEquals, []*currentSnapJSON{{
+			SnapID:    helloWorldSnapID,
+			Channel:   "stable",
+			Revision:  1,
+			Epoch:     "0",
+			CohortKey: "some-cohort-key",
+		}})
+		return []*snapDetails{{
+			Name:     "hello-world",
+			Revision: 26,
+			SnapID:   helloWorldSnapID,
+		}}, nil
+	})()
+
+	repo := New(nil, &testAuthContext{c: c, device: t.device})
+	c.Assert(repo, NotNil)
+
+	result, err := repo.LookupRefresh(&RefreshCandidate{
+		SnapID:    helloWorldSnapID,
+		Channel:   "stable",
+		CohortKey: "some-cohort-key",
+		Revision:  snap.R(1),
+		Epoch:     "0",
+	}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(result.Revision, Equals, snap.R(26))
+}
+
 func (t *remoteRepoTestSuite) TestUbuntuStoreRepositoryLookupRefreshLocalSnap(c *C) {
 	defer mockRFC(func(_ *Store, _ []*currentSnapJSON, _ *auth.UserState) ([]*snapDetails, error) {
 		panic("unexpected call to refreshForCandidates")
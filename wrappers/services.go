@@ -102,6 +102,15 @@ func StartServices(apps []*snap.AppInfo, inter interacter) (err error) {
 
 // AddSnapServices adds service units for the applications from the snap which are services.
 func AddSnapServices(s *snap.Info, inter interacter) (err error) {
+	return addSnapServices(s, true, inter)
+}
+
+// AddDisabledSnapServices adds service units for the applications from the snap which are services, without enabling them.
+func AddDisabledSnapServices(s *snap.Info, inter interacter) (err error) {
+	return addSnapServices(s, false, inter)
+}
+
+func addSnapServices(s *snap.Info, enable bool, inter interacter) (err error) {
 	sysd := systemd.New(dirs.GlobalRootDir, inter)
 	var written []string
 	var enabled []string
@@ -141,6 +150,9 @@ func AddSnapServices(s *snap.Info, inter interacter) (err error) {
 			return err
 		}
 		written = append(written, svcFilePath)
+		if !enable {
+			continue
+		}
 		svcName := app.ServiceName()
 		if err := sysd.Enable(svcName); err != nil {
 			return err
@@ -148,7 +160,7 @@ func AddSnapServices(s *snap.Info, inter interacter) (err error) {
 		enabled = append(enabled, svcName)
 	}
 
-	if len(enabled) > 0 {
+	if len(written) > 0 {
 		if err := sysd.DaemonReload(); err != nil {
 			return err
 		}